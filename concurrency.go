@@ -0,0 +1,197 @@
+package drydock
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+type renderResult struct {
+	contents   []byte
+	formatted  bool
+	renderTime time.Duration
+	err        error
+}
+
+// renderedContents is a [ContextWriterToFile] for contents which were already rendered.
+type renderedContents struct {
+	contents []byte
+}
+
+func (r *renderedContents) WriteToFileContext(ctx context.Context, _ WritableFS, _ string, w io.Writer) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	n, err := w.Write(r.contents)
+	return int64(n), err
+}
+
+func (g *FSGenerator) generateRealFiles(ctx context.Context, genfiles []*genfile) error {
+	if g.Concurrency > 1 {
+		return g.generateRealFilesConcurrently(ctx, genfiles)
+	}
+
+	for _, f := range genfiles {
+		if err := ctx.Err(); err != nil {
+			return g.fail("write", "", err)
+		}
+
+		err := g.generateRealFile(ctx, f)
+		if err != nil {
+			err = g.failed("write", f.path, err)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// generateRealFilesConcurrently renders all new files using a pool of [FSGenerator.Concurrency]
+// workers, while writing them in order. At most [FSGenerator.Concurrency] files are rendering or
+// waiting to be written at once, so the rendered contents are never all held in memory.
+func (g *FSGenerator) generateRealFilesConcurrently(ctx context.Context, genfiles []*genfile) error {
+	ctx, cancel := context.WithCancel(ctx)
+
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	results := make([]chan renderResult, len(genfiles))
+	for i, f := range genfiles {
+		if g.prerender(f) {
+			results[i] = make(chan renderResult, 1)
+		}
+	}
+
+	jobs := make(chan int)
+
+	// slots limits the files in flight, a slot is released once its file is written.
+	slots := make(chan struct{}, g.Concurrency)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(jobs)
+
+		for i := range results {
+			if results[i] == nil {
+				continue
+			}
+
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for range min(g.Concurrency, len(genfiles)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range jobs {
+				results[i] <- g.render(ctx, genfiles[i])
+			}
+		}()
+	}
+
+	for i, f := range genfiles {
+		if err := ctx.Err(); err != nil {
+			return g.fail("write", "", err)
+		}
+
+		if results[i] != nil {
+			var result renderResult
+
+			select {
+			case result = <-results[i]:
+			case <-ctx.Done():
+				return g.fail("write", "", ctx.Err())
+			}
+
+			if result.err != nil {
+				<-slots
+
+				err := g.failed("render", f.path, result.err)
+				if err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			rendered := *f
+			rendered.contents = &renderedContents{result.contents}
+			rendered.renderTime = result.renderTime
+			rendered.formatted = result.formatted
+			f = &rendered
+		}
+
+		err := g.generateRealFile(ctx, f)
+
+		if results[i] != nil {
+			<-slots
+		}
+
+		if err != nil {
+			err = g.failed("write", f.path, err)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// prerender reports whether f can be rendered before the files preceding it are written.
+func (g *FSGenerator) prerender(f *genfile) bool {
+	if !f.isNewFile || f.contents == nil || f.verbatim {
+		return false
+	}
+
+	formatter, ok := g.formatterFor(f.path).(FSReadingFormatter)
+
+	return !ok || !formatter.ReadsRootFS()
+}
+
+func (g *FSGenerator) render(ctx context.Context, f *genfile) renderResult {
+	if err := ctx.Err(); err != nil {
+		return renderResult{err: err}
+	}
+
+	start := time.Now()
+
+	if g.formatterFor(f.path) != nil {
+		formatted, err := g.format(ctx, g.fsys, f)
+		if err != nil {
+			return renderResult{err: err}
+		}
+
+		f = formatted
+	}
+
+	var b bytes.Buffer
+
+	_, err := f.contents.WriteToFileContext(ctx, g.fsys, f.path, &b)
+	if err != nil {
+		return renderResult{err: err}
+	}
+
+	return renderResult{contents: b.Bytes(), formatted: f.formatted, renderTime: time.Since(start)}
+}
@@ -0,0 +1,153 @@
+package drydock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFSGenerator_Generate_Concurrency(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmpdir := WritableMapFS{}
+
+	g := &FSGenerator{FS: tmpdir, Concurrency: 4}
+
+	files := make([]File, 0, 100)
+	for i := range 100 {
+		files = append(files, TemplateFile(fmt.Sprintf("file_%d.txt", i), "file {{ . }}", i))
+	}
+
+	type config struct {
+		Foo string `json:"foo"`
+	}
+
+	err := g.Generate(ctx,
+		Dir("a", files...),
+		Dir("b", files...),
+		PlainFile("config.json", `{"foo": "bar"}`),
+		ModifyFile("config.json", json.Unmarshal, func(c *config) ([]byte, error) {
+			c.Foo += "baz"
+			return json.Marshal(c)
+		}),
+	)
+	assert.NoError(t, err)
+
+	for i := range 100 {
+		contents, err := tmpdir.ReadFile(path.Join("b", fmt.Sprintf("file_%d.txt", i)))
+		assert.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("file %d", i), string(contents))
+	}
+
+	configJSON, err := tmpdir.ReadFile("config.json")
+	assert.NoError(t, err)
+	assert.Equal(t, `{"foo":"barbaz"}`, string(configJSON))
+}
+
+func TestFSGenerator_Generate_Concurrency_Error(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	errFirst := errors.New("first")
+	errSecond := errors.New("second")
+
+	for range 20 {
+		tmpdir := WritableMapFS{}
+
+		g := &FSGenerator{FS: tmpdir, Concurrency: 4}
+
+		err := g.Generate(ctx,
+			PlainFile("a", "a"),
+			&erroringFile{name: "b", err: errFirst},
+			PlainFile("c", "c"),
+			&erroringFile{name: "d", err: errSecond},
+			PlainFile("e", "e"),
+		)
+		assert.ErrorIs(t, err, errFirst)
+		assert.NotErrorIs(t, err, errSecond)
+
+		_, err = tmpdir.ReadFile("a")
+		assert.NoError(t, err)
+
+		_, err = tmpdir.ReadFile("c")
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	}
+}
+
+func TestFSGenerator_Generate_Concurrency_Bounded(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	var rendered, written, maxInFlight atomic.Int64
+
+	files := make([]File, 0, 50)
+	for i := range 50 {
+		files = append(files, FileFunc(fmt.Sprintf("file_%d.txt", i), func(context.Context, WritableFS, string) ([]byte, error) {
+			inFlight := rendered.Add(1) - written.Load()
+			for {
+				prev := maxInFlight.Load()
+				if inFlight <= prev || maxInFlight.CompareAndSwap(prev, inFlight) {
+					break
+				}
+			}
+
+			return []byte("contents"), nil
+		}))
+	}
+
+	g := &FSGenerator{
+		FS:          WritableMapFS{},
+		Concurrency: 4,
+		Observer: &ObserverFuncs{OnFileWritten: func(ReportEntry) {
+			// slow writer, so the workers would render ahead without a bound
+			time.Sleep(time.Millisecond)
+			written.Add(1)
+		}},
+	}
+
+	err := g.Generate(ctx, files...)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(50), written.Load())
+	assert.LessOrEqual(t, maxInFlight.Load(), int64(4))
+}
+
+func TestFSGenerator_Generate_Concurrency_RemoveTemp(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	basedir := t.TempDir()
+	assert.NoError(t, os.WriteFile(path.Join(basedir, "config.json"), []byte(`{"foo": "bar"}`), 0600))
+
+	errModify := errors.New("modify failed")
+
+	type config struct {
+		Foo string `json:"foo"`
+	}
+
+	g := &FSGenerator{FS: NewWritableDirFS(basedir), Concurrency: 2}
+
+	err := g.Generate(ctx,
+		PlainFile("a", "a"),
+		ModifyFile("config.json", json.Unmarshal, func(c *config) ([]byte, error) {
+			return nil, errModify
+		}),
+	)
+	assert.ErrorIs(t, err, errModify)
+	assert.NotContains(t, err.Error(), "remove")
+
+	entries, err := os.ReadDir(tmp)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -0,0 +1,168 @@
+package drydock
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"time"
+)
+
+// ErrConflict is returned when a [ConflictResolver] resolved a conflict with [ConflictFail].
+var ErrConflict = errors.New("conflicting file")
+
+// Resolution decides what happens to an existing file which would be overwritten.
+// A Resolution is also a [ConflictResolver] which resolves every conflict the same way.
+type Resolution int
+
+const (
+	// ConflictOverwrite replaces the existing file.
+	ConflictOverwrite Resolution = iota + 1
+	// ConflictSkip keeps the existing file and discards the new contents.
+	ConflictSkip
+	// ConflictKeepBoth keeps the existing file and writes the new contents next to it with
+	// the suffix `.new`.
+	ConflictKeepBoth
+	// ConflictBackup copies the existing file to a file with the suffix `.orig` before
+	// replacing it.
+	ConflictBackup
+	// ConflictFail aborts the generation with [ErrConflict].
+	ConflictFail
+)
+
+func (r Resolution) String() string {
+	switch r {
+	case ConflictOverwrite:
+		return "overwrite"
+	case ConflictSkip:
+		return "skip"
+	case ConflictKeepBoth:
+		return "keep-both"
+	case ConflictBackup:
+		return "backup"
+	case ConflictFail:
+		return "fail"
+	default:
+		return fmt.Sprintf("Resolution(%d)", int(r))
+	}
+}
+
+// ResolveConflict implements [ConflictResolver].
+func (r Resolution) ResolveConflict(context.Context, *Conflict) (Resolution, error) {
+	return r, nil
+}
+
+// Conflict is a file which already exists and would be overwritten.
+type Conflict struct {
+	Path     string
+	Existing []byte
+	New      []byte
+
+	// Target is set instead of New if the existing file would be replaced by a symbolic link
+	// to Target, see [Symlink].
+	Target string
+
+	// Verbatim is true if New is not set, because the new file is copied verbatim and never
+	// loaded into memory, see [CopyFile].
+	Verbatim bool
+
+	// Edited is true if the existing file was generated by a previous run and edited since.
+	// It is only set when [FSGenerator.ManifestPath] is set.
+	Edited bool
+}
+
+// ConflictResolver decides how a [Conflict] is resolved.
+type ConflictResolver interface {
+	ResolveConflict(ctx context.Context, c *Conflict) (Resolution, error)
+}
+
+// ConflictResolverFunc is a function implementing [ConflictResolver].
+type ConflictResolverFunc func(ctx context.Context, c *Conflict) (Resolution, error)
+
+// ResolveConflict implements [ConflictResolver].
+func (f ConflictResolverFunc) ResolveConflict(ctx context.Context, c *Conflict) (Resolution, error) {
+	return f(ctx, c)
+}
+
+const (
+	keepBothSuffix = ".new"
+	backupSuffix   = ".orig"
+)
+
+// resolveConflict returns the file which should be written instead of file and whether it
+// already exists. A nil file means the file was skipped.
+func (g *FSGenerator) resolveConflict(ctx context.Context, file *genfile) (*genfile, bool, error) {
+	existing, err := g.fsys.ReadFile(file.path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	conflict := &Conflict{Path: file.path, Existing: existing, Target: file.symlink, Verbatim: file.verbatim}
+	if g.manifests != nil {
+		conflict.Edited = g.manifests.prev.Generated(file.path)
+	}
+
+	rendered := *file
+
+	if file.symlink == "" && !file.verbatim {
+		var b bytes.Buffer
+
+		_, err = file.contents.WriteToFileContext(ctx, g.fsys, file.path, &b)
+		if err != nil {
+			return nil, false, &GenerateError{Path: file.path, Op: "render", Err: err}
+		}
+
+		conflict.New = b.Bytes()
+		rendered.contents = &renderedContents{b.Bytes()}
+	}
+
+	resolution, err := g.ConflictResolver.ResolveConflict(ctx, conflict)
+	if err != nil {
+		return nil, false, err
+	}
+
+	switch resolution {
+	case ConflictOverwrite:
+		return &rendered, true, nil
+	case ConflictSkip:
+		g.keepFile(file.path)
+		g.skipped(file.path)
+		return nil, false, nil
+	case ConflictKeepBoth:
+		g.keepFile(file.path)
+		rendered.path += keepBothSuffix
+		rendered.noManifest = true
+		existed, err := fileExists(g.fsys, rendered.path)
+		return &rendered, existed, err
+	case ConflictBackup:
+		err = g.backupFile(ctx, file.path, existing)
+		return &rendered, true, err
+	case ConflictFail:
+		return nil, false, fmt.Errorf("%w: %s: %w", ErrConflict, file.path, fs.ErrExist)
+	default:
+		return nil, false, fmt.Errorf("%w: %s: unknown resolution %s", ErrConflict, file.path, resolution)
+	}
+}
+
+func (g *FSGenerator) backupFile(ctx context.Context, p string, contents []byte) error {
+	stat, err := statFile(g.fsys, p)
+	if err != nil {
+		return err
+	}
+
+	backup := &genfile{
+		path:       p + backupSuffix,
+		contents:   &renderedContents{contents},
+		isNewFile:  true,
+		noManifest: true,
+		mode:       stat.Mode().Perm(),
+	}
+
+	existed, err := fileExists(g.fsys, backup.path)
+	if err != nil {
+		return err
+	}
+
+	return g.writeRealFile(ctx, backup, existed, time.Now())
+}
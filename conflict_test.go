@@ -0,0 +1,121 @@
+package drydock
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFSGenerator_Generate_ConflictResolver(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tt := []struct {
+		name     string
+		resolver ConflictResolver
+		exp      map[string]string
+		err      error
+	}{
+		{
+			name:     "Overwrite",
+			resolver: ConflictOverwrite,
+			exp:      map[string]string{"README.md": "new readme", "main.go": "package main"},
+		},
+		{
+			name:     "Skip",
+			resolver: ConflictSkip,
+			exp:      map[string]string{"README.md": "old readme", "main.go": "package main"},
+		},
+		{
+			name:     "Keep Both",
+			resolver: ConflictKeepBoth,
+			exp:      map[string]string{"README.md": "old readme", "README.md.new": "new readme", "main.go": "package main"},
+		},
+		{
+			name:     "Backup",
+			resolver: ConflictBackup,
+			exp:      map[string]string{"README.md": "new readme", "README.md.orig": "old readme", "main.go": "package main"},
+		},
+		{
+			name:     "Fail",
+			resolver: ConflictFail,
+			exp:      map[string]string{"README.md": "old readme"},
+			err:      ErrConflict,
+		},
+		{
+			name: "Func",
+			resolver: ConflictResolverFunc(func(_ context.Context, c *Conflict) (Resolution, error) {
+				if string(c.Existing) == "old readme" && string(c.New) == "new readme" {
+					return ConflictKeepBoth, nil
+				}
+
+				return ConflictFail, nil
+			}),
+			exp: map[string]string{"README.md": "old readme", "README.md.new": "new readme", "main.go": "package main"},
+		},
+	}
+
+	for _, tt := range tt {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpdir := WritableMapFS{
+				"README.md": &fstest.MapFile{Data: []byte("old readme")},
+			}
+
+			g := &FSGenerator{FS: tmpdir, ConflictResolver: tt.resolver}
+
+			err := g.Generate(ctx,
+				PlainFile("README.md", "new readme"),
+				PlainFile("main.go", "package main"),
+			)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			actual := map[string]string{}
+			for name, f := range tmpdir {
+				actual[name] = string(f.Data)
+			}
+
+			assert.Equal(t, tt.exp, actual)
+		})
+	}
+}
+
+func TestFSGenerator_Plan_ConflictResolver(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmpdir := WritableMapFS{
+		"README.md": &fstest.MapFile{Data: []byte("old readme")},
+	}
+
+	g := &FSGenerator{FS: tmpdir, ConflictResolver: ConflictBackup}
+
+	plan, err := g.Plan(ctx, PlainFile("README.md", "new readme"))
+	assert.NoError(t, err)
+	assert.Equal(t, "create README.md.orig\noverwrite README.md\n", plan.String())
+
+	g.ConflictResolver = ConflictSkip
+
+	plan, err = g.Plan(ctx, PlainFile("README.md", "new readme"))
+	assert.NoError(t, err)
+	assert.Empty(t, plan.Operations)
+
+	g.ConflictResolver = ConflictKeepBoth
+	tmpdir["README.md.new"] = &fstest.MapFile{Data: []byte("older new readme")}
+
+	plan, err = g.Plan(ctx, PlainFile("README.md", "new readme"))
+	assert.NoError(t, err)
+	assert.Equal(t, "overwrite README.md.new\n", plan.String())
+
+	err = g.Generate(ctx, PlainFile("README.md", "new readme"))
+	assert.NoError(t, err)
+
+	contents, err := tmpdir.ReadFile("README.md.new")
+	assert.NoError(t, err)
+	assert.Equal(t, "new readme", string(contents))
+}
@@ -0,0 +1,287 @@
+package drydock
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"time"
+)
+
+// CopyOption configures [CopyFS] and [CopyFile].
+type CopyOption func(*copyOptions)
+
+type copyOptions struct {
+	preserveMode    bool
+	preserveModTime bool
+	include         []string
+	exclude         []string
+}
+
+func newCopyOptions(opts []CopyOption) copyOptions {
+	var o copyOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// WithPreserveMode keeps the permissions of the copied files and directories.
+func WithPreserveMode() CopyOption {
+	return func(o *copyOptions) {
+		o.preserveMode = true
+	}
+}
+
+// WithPreserveModTime keeps the modification time of the copied files.
+func WithPreserveModTime() CopyOption {
+	return func(o *copyOptions) {
+		o.preserveModTime = true
+	}
+}
+
+// WithInclude only copies files matching one of the [path.Match] patterns. Patterns are matched
+// against the path relative to the copied directory and against the file name, so `*.png`
+// matches PNG files in every directory. Directories without included files are skipped.
+func WithInclude(patterns ...string) CopyOption {
+	return func(o *copyOptions) {
+		o.include = append(o.include, patterns...)
+	}
+}
+
+// WithExclude skips files and directories matching one of the [path.Match] patterns, which are
+// matched like the patterns of [WithInclude]. Exclude patterns take precedence over include patterns.
+func WithExclude(patterns ...string) CopyOption {
+	return func(o *copyOptions) {
+		o.exclude = append(o.exclude, patterns...)
+	}
+}
+
+// CopyFS creates a directory with the contents of src. Files are copied verbatim: they are
+// streamed from src when they are written, so they are never loaded into memory as a whole,
+// and they are not formatted by [FSGenerator.Formatters].
+func CopyFS(name string, src fs.FS, opts ...CopyOption) Directory {
+	return &copyDir{name: name, src: src, dir: ".", opts: newCopyOptions(opts)}
+}
+
+// CopyFile creates a file with the contents of the file at p in src. Like the files of [CopyFS],
+// it is copied verbatim. Include and exclude patterns are ignored.
+func CopyFile(name string, src fs.FS, p string, opts ...CopyOption) File {
+	return &copyFile{name: name, src: src, path: p, opts: newCopyOptions(opts)}
+}
+
+type copyDir struct {
+	name string
+	src  fs.FS
+	dir  string
+	opts copyOptions
+}
+
+func (d *copyDir) Name() string {
+	return d.name
+}
+
+// Mode implements [FileMode].
+func (d *copyDir) Mode() fs.FileMode {
+	return statMode(d.src, d.dir, d.opts)
+}
+
+func (d *copyDir) Entries() ([]File, error) {
+	entries, err := fs.ReadDir(d.src, d.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]File, 0, len(entries))
+
+	for _, entry := range entries {
+		p := path.Join(d.dir, entry.Name())
+
+		excluded, err := matchAny(d.opts.exclude, p)
+		if err != nil {
+			return nil, err
+		}
+
+		if excluded {
+			continue
+		}
+
+		if entry.IsDir() {
+			child := &copyDir{name: entry.Name(), src: d.src, dir: p, opts: d.opts}
+
+			if len(d.opts.include) != 0 {
+				children, err := child.Entries()
+				if err != nil {
+					return nil, err
+				}
+
+				if len(children) == 0 {
+					continue
+				}
+			}
+
+			files = append(files, child)
+
+			continue
+		}
+
+		if len(d.opts.include) != 0 {
+			included, err := matchAny(d.opts.include, p)
+			if err != nil {
+				return nil, err
+			}
+
+			if !included {
+				continue
+			}
+		}
+
+		files = append(files, &copyFile{name: entry.Name(), src: d.src, path: p, opts: d.opts})
+	}
+
+	return files, nil
+}
+
+type copyFile struct {
+	name string
+	src  fs.FS
+	path string
+	opts copyOptions
+}
+
+func (f *copyFile) Name() string {
+	return f.name
+}
+
+func (f *copyFile) verbatim() bool {
+	return true
+}
+
+// Mode implements [FileMode].
+func (f *copyFile) Mode() fs.FileMode {
+	return statMode(f.src, f.path, f.opts)
+}
+
+// ModTime implements [FileModTime].
+func (f *copyFile) ModTime() time.Time {
+	if !f.opts.preserveModTime {
+		return time.Time{}
+	}
+
+	info, err := fs.Stat(f.src, f.path)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return info.ModTime()
+}
+
+// WriteTo implements [io.WriterTo]
+func (f *copyFile) WriteTo(w io.Writer) (int64, error) {
+	src, err := f.src.Open(f.path)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	return io.Copy(w, src)
+}
+
+// isVerbatim reports whether f is copied verbatim, see [CopyFile]. Verbatim files are not
+// formatted and not rendered before they are written, but streamed.
+func isVerbatim(f File) bool {
+	v, ok := f.(interface{ verbatim() bool })
+	return ok && v.verbatim()
+}
+
+// errContentsDiffer stops comparing contents at the first difference.
+var errContentsDiffer = errors.New("contents differ")
+
+// compareWriter compares everything written to it with the contents of r.
+type compareWriter struct {
+	r   io.Reader
+	buf []byte
+}
+
+func (c *compareWriter) Write(p []byte) (int, error) {
+	if cap(c.buf) < len(p) {
+		c.buf = make([]byte, len(p))
+	}
+
+	buf := c.buf[:len(p)]
+
+	_, err := io.ReadFull(c.r, buf)
+	if err != nil || !bytes.Equal(buf, p) {
+		return 0, errContentsDiffer
+	}
+
+	return len(p), nil
+}
+
+// equalContents compares the contents of file with the existing file in fsys chunk by chunk,
+// without loading either into memory. If they are equal, the hash of the contents is returned.
+func equalContents(ctx context.Context, fsys WritableFS, file *genfile) (bool, string, error) {
+	existing, err := fsys.Open(file.path)
+	if err != nil {
+		return false, "", err
+	}
+	defer existing.Close()
+
+	hash := sha256.New()
+	r := io.TeeReader(existing, hash)
+
+	_, err = file.contents.WriteToFileContext(ctx, fsys, file.path, &compareWriter{r: r})
+	if errors.Is(err, errContentsDiffer) {
+		return false, "", nil
+	}
+
+	if err != nil {
+		return false, "", &GenerateError{Path: file.path, Op: "render", Err: err}
+	}
+
+	// the existing file must not be longer
+	n, err := r.Read(make([]byte, 1))
+	if n != 0 || !errors.Is(err, io.EOF) {
+		return false, "", nil
+	}
+
+	return true, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// statMode returns the permissions of p in src, if they should be preserved. Errors are reported
+// when p is read, so they are ignored here.
+func statMode(src fs.FS, p string, opts copyOptions) fs.FileMode {
+	if !opts.preserveMode {
+		return 0
+	}
+
+	info, err := fs.Stat(src, p)
+	if err != nil {
+		return 0
+	}
+
+	return info.Mode().Perm()
+}
+
+// matchAny reports whether p or its base name match one of the patterns.
+func matchAny(patterns []string, p string) (bool, error) {
+	for _, pattern := range patterns {
+		for _, name := range []string{p, path.Base(p)} {
+			matched, err := path.Match(pattern, name)
+			if err != nil {
+				return false, err
+			}
+
+			if matched {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
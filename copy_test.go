@@ -0,0 +1,154 @@
+package drydock
+
+import (
+	"context"
+	"io/fs"
+	"path"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCopyFS(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	assets := fstest.MapFS{
+		"logo.png":         &fstest.MapFile{Data: []byte("png"), Mode: 0600, ModTime: modTime},
+		"img/icon.png":     &fstest.MapFile{Data: []byte("icon"), ModTime: modTime},
+		"img/icon.svg":     &fstest.MapFile{Data: []byte("svg")},
+		"img/raw/big.psd":  &fstest.MapFile{Data: []byte("psd")},
+		"scripts/build.sh": &fstest.MapFile{Data: []byte("#!/bin/sh"), Mode: 0755},
+		"tmp/cache.png":    &fstest.MapFile{Data: []byte("cache")},
+	}
+
+	t.Run("All", func(t *testing.T) {
+		tmpdir := WritableMapFS{}
+
+		err := (&FSGenerator{FS: tmpdir}).Generate(ctx, CopyFS("assets", assets))
+		assert.NoError(t, err)
+		assert.Equal(t, "png", string(tmpdir["assets/logo.png"].Data))
+		assert.Equal(t, "psd", string(tmpdir["assets/img/raw/big.psd"].Data))
+		assert.Equal(t, "#!/bin/sh", string(tmpdir["assets/scripts/build.sh"].Data))
+		assert.NotEqual(t, fs.FileMode(0755), tmpdir["assets/scripts/build.sh"].Mode.Perm())
+		assert.NotEqual(t, modTime, tmpdir["assets/logo.png"].ModTime)
+	})
+
+	t.Run("Preserve", func(t *testing.T) {
+		tmpdir := WritableMapFS{}
+
+		err := (&FSGenerator{FS: tmpdir}).Generate(ctx, CopyFS("assets", assets, WithPreserveMode(), WithPreserveModTime()))
+		assert.NoError(t, err)
+		assert.Equal(t, fs.FileMode(0755), tmpdir["assets/scripts/build.sh"].Mode.Perm())
+		assert.Equal(t, fs.FileMode(0600), tmpdir["assets/logo.png"].Mode.Perm())
+		assert.Equal(t, modTime, tmpdir["assets/logo.png"].ModTime)
+		assert.Equal(t, modTime, tmpdir["assets/img/icon.png"].ModTime)
+	})
+
+	t.Run("Filter", func(t *testing.T) {
+		tmpdir := WritableMapFS{}
+
+		err := (&FSGenerator{FS: tmpdir}).Generate(ctx, CopyFS("assets", assets, WithInclude("*.png", "*.psd"), WithExclude("tmp", "img/raw/*")))
+		assert.NoError(t, err)
+		assert.Equal(t, WritableMapFS{
+			"assets":              tmpdir["assets"],
+			"assets/img":          tmpdir["assets/img"],
+			"assets/img/icon.png": tmpdir["assets/img/icon.png"],
+			"assets/logo.png":     tmpdir["assets/logo.png"],
+		}, tmpdir)
+
+		err = (&FSGenerator{FS: tmpdir}).Generate(ctx, CopyFS("assets", assets, WithExclude("[")))
+		assert.ErrorIs(t, err, path.ErrBadPattern)
+	})
+}
+
+func TestCopyFile(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	assets := fstest.MapFS{
+		"configs/nginx.conf": &fstest.MapFile{Data: []byte("server {}"), Mode: 0640},
+	}
+
+	tmpdir := WritableMapFS{}
+
+	err := (&FSGenerator{FS: tmpdir}).Generate(ctx, Dir("etc", CopyFile("nginx.conf", assets, "configs/nginx.conf", WithPreserveMode())))
+	assert.NoError(t, err)
+	assert.Equal(t, "server {}", string(tmpdir["etc/nginx.conf"].Data))
+	assert.Equal(t, fs.FileMode(0640), tmpdir["etc/nginx.conf"].Mode.Perm())
+
+	err = (&FSGenerator{FS: tmpdir}).Generate(ctx, CopyFile("missing.conf", assets, "configs/missing.conf"))
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestCopyFS_Verbatim(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	png := "\x89PNG  \r\n\x1a\n   \n\n"
+	cfg := "{\"a\":  1}  \n"
+
+	assets := fstest.MapFS{
+		"logo.png": &fstest.MapFile{Data: []byte(png)},
+		"cfg.json": &fstest.MapFile{Data: []byte(cfg)},
+		"main.go":  &fstest.MapFile{Data: []byte("package main\nfunc main(){}")},
+	}
+
+	tmpdir := WritableMapFS{}
+
+	g := &FSGenerator{FS: tmpdir, Formatters: DefaultFormatters(), Concurrency: 4, SkipUnchanged: true}
+
+	err := g.Generate(ctx, CopyFS("assets", assets), PlainFile("other.json", `{"a":1}`))
+	assert.NoError(t, err)
+	assert.Equal(t, png, string(tmpdir["assets/logo.png"].Data))
+	assert.Equal(t, cfg, string(tmpdir["assets/cfg.json"].Data))
+	assert.Equal(t, "package main\nfunc main(){}", string(tmpdir["assets/main.go"].Data))
+	assert.Equal(t, "{\n  \"a\": 1\n}\n", string(tmpdir["other.json"].Data))
+
+	plan, err := g.Plan(ctx, CopyFS("assets", assets))
+	assert.NoError(t, err)
+	assert.Empty(t, plan.Operations)
+
+	report, err := g.GenerateWithReport(ctx, CopyFS("assets", assets))
+	assert.NoError(t, err)
+
+	for _, entry := range report.Entries {
+		if entry.Path != "assets" {
+			assert.Equal(t, ActionUnchanged, entry.Action, entry.Path)
+		}
+	}
+
+	for _, existing := range []string{png[:4], png + "x", "\x89PNG  \r\n\x1a\n   \n\r"} {
+		tmpdir["assets/logo.png"].Data = []byte(existing)
+
+		report, err = g.GenerateWithReport(ctx, Dir("assets", CopyFile("logo.png", assets, "logo.png")))
+		assert.NoError(t, err)
+		assert.Contains(t, clearDurations(report.Entries), ReportEntry{Path: "assets/logo.png", Action: ActionOverwritten, Bytes: int64(len(png))})
+		assert.Equal(t, png, string(tmpdir["assets/logo.png"].Data))
+	}
+}
+
+func TestCopyFile_Conflict(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	assets := fstest.MapFS{"logo.png": &fstest.MapFile{Data: []byte("new")}}
+	tmpdir := WritableMapFS{"logo.png": &fstest.MapFile{Data: []byte("old")}}
+
+	var conflict *Conflict
+
+	g := &FSGenerator{FS: tmpdir, ConflictResolver: ConflictResolverFunc(func(_ context.Context, c *Conflict) (Resolution, error) {
+		conflict = c
+		return ConflictKeepBoth, nil
+	})}
+
+	err := g.Generate(ctx, CopyFile("logo.png", assets, "logo.png"))
+	assert.NoError(t, err)
+	assert.Equal(t, &Conflict{Path: "logo.png", Existing: []byte("old"), Verbatim: true}, conflict)
+	assert.Equal(t, "old", string(tmpdir["logo.png"].Data))
+	assert.Equal(t, "new", string(tmpdir["logo.png.new"].Data))
+}
@@ -1,6 +1,7 @@
 package drydock
 
 import (
+	"io/fs"
 	"strings"
 )
 
@@ -8,6 +9,20 @@ func Dir(name string, entries ...File) Directory {
 	return &dir{name: name, entries: entries}
 }
 
+// TemplateNamedDir is like [Dir], but name is a template, which is executed with data, e.g.
+// `{{ .Module }}`. Errors executing the name template and invalid rendered names are reported
+// by [Validate] and [FSGenerator.Generate] before anything is written.
+func TemplateNamedDir(name string, data any, entries ...File) Directory {
+	rendered, err := renderName(name, data, templateOptions{})
+
+	return &dir{name: rendered, nameErr: err, entries: entries}
+}
+
+// DirMode is like [Dir] but sets the permissions of the directory.
+func DirMode(name string, mode fs.FileMode, entries ...File) Directory {
+	return &dir{name: name, entries: entries, mode: mode}
+}
+
 // DirP is like [Dir] but [name] can be a file path and every
 // segment will be created as a directory, similar to [os.MkdirAll] or
 // the `mkdir -p` command.
@@ -29,13 +44,24 @@ func DirP(name string, entries ...File) Directory {
 
 type dir struct {
 	name    string
+	nameErr error
 	entries []File
+	mode    fs.FileMode
 }
 
 func (d *dir) Name() string {
 	return d.name
 }
 
+func (d *dir) nameError() error {
+	return d.nameErr
+}
+
 func (d *dir) Entries() ([]File, error) {
 	return d.entries, nil
 }
+
+// Mode implements [FileMode].
+func (d *dir) Mode() fs.FileMode {
+	return d.mode
+}
@@ -10,6 +10,16 @@ type DirFSGenerator struct {
 	NoCreateOutputDir   bool
 	CleanDir            bool
 	ErrorOnExistingFile bool
+	Transactional       bool
+	Concurrency         int
+	Observer            Observer
+	ConflictResolver    ConflictResolver
+	ManifestPath        string
+	Blueprint           string
+	Prune               bool
+	SkipUnchanged       bool
+	ContinueOnError     bool
+	Formatters          map[string]Formatter
 }
 
 func (g *DirFSGenerator) Generate(ctx context.Context, files ...File) error {
@@ -31,6 +41,16 @@ func (g *DirFSGenerator) Generate(ctx context.Context, files ...File) error {
 		CleanDir:            g.CleanDir,
 		ErrorOnExistingDir:  g.ErrorOnExistingDir,
 		ErrorOnExistingFile: g.ErrorOnExistingFile,
+		Transactional:       g.Transactional,
+		Concurrency:         g.Concurrency,
+		Observer:            g.Observer,
+		ConflictResolver:    g.ConflictResolver,
+		ManifestPath:        g.ManifestPath,
+		Blueprint:           g.Blueprint,
+		Prune:               g.Prune,
+		SkipUnchanged:       g.SkipUnchanged,
+		ContinueOnError:     g.ContinueOnError,
+		Formatters:          g.Formatters,
 	}
 
 	return fsgen.Generate(ctx, files...)
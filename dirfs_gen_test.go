@@ -8,6 +8,7 @@ import (
 	"path"
 	"testing"
 	"text/template"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -319,3 +320,77 @@ func TestFSGenerator_Generate_NoCreateOutputDir(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "contents", string(actual))
 }
+
+func TestDirFSGenerator_Generate_SkipUnchanged(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmpdir := t.TempDir()
+
+	g := &DirFSGenerator{OutputDir: tmpdir, SkipUnchanged: true}
+
+	err := g.Generate(ctx, PlainFile("test.txt", "contents"))
+	assert.NoError(t, err)
+
+	modTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	err = os.Chtimes(path.Join(tmpdir, "test.txt"), modTime, modTime)
+	assert.NoError(t, err)
+
+	err = g.Generate(ctx, PlainFile("test.txt", "contents"))
+	assert.NoError(t, err)
+
+	stat, err := os.Stat(path.Join(tmpdir, "test.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, modTime, stat.ModTime())
+}
+
+func TestDirFSGenerator_Generate_SkipUnchanged_Mode(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmpdir := t.TempDir()
+
+	err := os.WriteFile(path.Join(tmpdir, "build.sh"), []byte("#!/bin/sh"), 0644)
+	assert.NoError(t, err)
+
+	g := &DirFSGenerator{OutputDir: tmpdir, SkipUnchanged: true}
+
+	err = g.Generate(ctx, PlainFile("build.sh", "#!/bin/sh", WithMode(0755)))
+	assert.NoError(t, err)
+
+	stat, err := os.Stat(path.Join(tmpdir, "build.sh"))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), stat.Mode())
+}
+
+func TestDirFSGenerator_Generate_FileModes(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmpdir := t.TempDir()
+
+	err := os.WriteFile(path.Join(tmpdir, "private.txt"), []byte("old"), 0600)
+	assert.NoError(t, err)
+
+	g := &DirFSGenerator{OutputDir: tmpdir}
+
+	err = g.Generate(ctx,
+		PlainFile("README.md", "readme"),
+		PlainFile("private.txt", "new"),
+		DirMode("scripts", 0700,
+			PlainFile("build.sh", "#!/bin/sh", WithMode(0755)),
+		),
+	)
+	assert.NoError(t, err)
+
+	for name, mode := range map[string]os.FileMode{
+		"README.md":        0644,
+		"private.txt":      0600,
+		"scripts":          0700 | os.ModeDir,
+		"scripts/build.sh": 0755,
+	} {
+		stat, err := os.Stat(path.Join(tmpdir, name))
+		assert.NoError(t, err)
+		assert.Equal(t, mode, stat.Mode(), name)
+	}
+}
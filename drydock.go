@@ -1,11 +1,13 @@
 package drydock
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"path"
+	"time"
 )
 
 type File interface {
@@ -21,16 +23,88 @@ type WriterToFile interface {
 	WriteToFile(rootFS WritableFS, filename string, w io.Writer) (n int64, err error)
 }
 
+// ContextWriterToFile is like [WriterToFile], but receives the context passed to
+// [FSGenerator.Generate], so long running writers can be cancelled. It takes precedence over
+// [WriterToFile] and [io.WriterTo].
+type ContextWriterToFile interface {
+	WriteToFileContext(ctx context.Context, rootFS WritableFS, filename string, w io.Writer) (n int64, err error)
+}
+
 type IsNewFile interface {
 	IsNewFile() bool
 }
 
+// FileMode can be implemented by a [File] or [Directory] to set its permissions. A mode of 0
+// uses the default mode. The mode is only applied if the [WritableFS] implements [ChmodFS].
+type FileMode interface {
+	Mode() fs.FileMode
+}
+
+// FileModTime can be implemented by a [File] to set its modification time. A zero time keeps the
+// time the file was written. The time is only applied if the [WritableFS] implements [ChtimesFS].
+type FileModTime interface {
+	ModTime() time.Time
+}
+
 type writerToAdapter struct {
 	io.WriterTo
 }
 
-func (a *writerToAdapter) WriteToFile(_ WritableFS, _ string, w io.Writer) (n int64, err error) {
-	return a.WriteTo(w)
+func (a *writerToAdapter) WriteToFileContext(ctx context.Context, _ WritableFS, _ string, w io.Writer) (n int64, err error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return a.WriteTo(&contextWriter{ctx: ctx, w: w})
+}
+
+type writerToFileAdapter struct {
+	WriterToFile
+}
+
+func (a *writerToFileAdapter) WriteToFileContext(
+	ctx context.Context,
+	rootFS WritableFS,
+	filename string,
+	w io.Writer,
+) (n int64, err error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return a.WriteToFile(rootFS, filename, &contextWriter{ctx: ctx, w: w})
+}
+
+// contextWriter fails writes to w once ctx is done, so writers unaware of the context stop
+// on their next write.
+type contextWriter struct {
+	ctx context.Context //nolint:containedctx // only lives as long as a single write
+	w   io.Writer
+}
+
+func (c *contextWriter) Write(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return c.w.Write(p)
+}
+
+// countingWriter counts the bytes written to w and records the error of w, if any.
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	if err != nil {
+		c.err = err
+	}
+
+	return n, err
 }
 
 var ErrCleaningOutputDir = errors.New("error cleaning output dir")
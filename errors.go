@@ -0,0 +1,58 @@
+package drydock
+
+import (
+	"errors"
+)
+
+// GenerateError records the path and the operation which failed during [FSGenerator.Generate].
+// Op is one of "walk", "validate", "clean", "mkdir", "render", "format", "write", "remove" or
+// "manifest". Path is empty if the error is not related to a single path.
+type GenerateError struct {
+	Path string
+	Op   string
+	Err  error
+}
+
+func (e *GenerateError) Error() string {
+	// a FormatError already contains the path together with the line of the error
+	var formatErr *FormatError
+	if e.Path == "" || (errors.As(e.Err, &formatErr) && formatErr.Path == e.Path) {
+		return e.Op + ": " + e.Err.Error()
+	}
+
+	return e.Op + " " + e.Path + ": " + e.Err.Error()
+}
+
+func (e *GenerateError) Unwrap() error {
+	return e.Err
+}
+
+// fail notifies the [Observer] about err and returns it as a [GenerateError], unless it
+// already is one.
+func (g *FSGenerator) fail(op string, path string, err error) error {
+	var genErr *GenerateError
+	if !errors.As(err, &genErr) {
+		err = &GenerateError{Path: path, Op: op, Err: err}
+	}
+
+	g.observer().Error(path, err)
+
+	return err
+}
+
+// failed is like fail, but with [FSGenerator.ContinueOnError] the error is recorded and nil is
+// returned, so generation continues. Recorded errors are joined and returned by
+// [FSGenerator.Generate].
+func (g *FSGenerator) failed(op string, path string, err error) error {
+	err = g.fail(op, path, err)
+	if !g.ContinueOnError {
+		return err
+	}
+
+	g.errs = append(g.errs, err)
+
+	// keep the previous manifest entry of files which weren't written
+	g.keepFile(path)
+
+	return nil
+}
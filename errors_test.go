@@ -0,0 +1,167 @@
+package drydock
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFSGenerator_Generate_GenerateError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	errFailing := errors.New("failing file")
+
+	tt := []struct {
+		name  string
+		fsys  WritableFS
+		g     FSGenerator
+		files []File
+		path  string
+		op    string
+		err   error
+	}{
+		{
+			name:  "Render",
+			fsys:  WritableMapFS{},
+			files: []File{Dir("dir", &erroringFile{name: "fail", err: errFailing})},
+			path:  "dir/fail",
+			op:    "render",
+			err:   errFailing,
+		},
+		{
+			name:  "Template",
+			fsys:  WritableMapFS{},
+			files: []File{TemplateFile("broken.txt", "{{ index .List 5 }}", map[string]any{"List": []int{}})},
+			path:  "broken.txt",
+			op:    "render",
+		},
+		{
+			name:  "Format",
+			fsys:  WritableMapFS{},
+			g:     FSGenerator{Formatters: DefaultFormatters()},
+			files: []File{PlainFile("main.go", "package")},
+			path:  "main.go",
+			op:    "format",
+		},
+		{
+			name:  "Mkdir",
+			fsys:  WritableMapFS{"dir": &fstest.MapFile{Mode: fs.ModeDir}},
+			g:     FSGenerator{ErrorOnExistingDir: true},
+			files: []File{Dir("dir")},
+			path:  "dir",
+			op:    "mkdir",
+			err:   fs.ErrExist,
+		},
+		{
+			name:  "Write",
+			fsys:  WritableMapFS{"file": &fstest.MapFile{}},
+			g:     FSGenerator{ErrorOnExistingFile: true},
+			files: []File{PlainFile("file", "")},
+			path:  "file",
+			op:    "write",
+			err:   fs.ErrExist,
+		},
+		{
+			name:  "Manifest",
+			fsys:  WritableMapFS{"manifest.json": &fstest.MapFile{Data: []byte("{")}},
+			g:     FSGenerator{ManifestPath: "manifest.json"},
+			files: []File{PlainFile("file", "")},
+			path:  "manifest.json",
+			op:    "manifest",
+			err:   ErrInvalidManifest,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			g := tc.g
+			g.FS = tc.fsys
+
+			err := g.Generate(ctx, tc.files...)
+
+			var genErr *GenerateError
+			if assert.ErrorAs(t, err, &genErr) {
+				assert.Equal(t, tc.path, genErr.Path)
+				assert.Equal(t, tc.op, genErr.Op)
+			}
+
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestGenerateError_Error(t *testing.T) {
+	err := &GenerateError{Path: "dir/file", Op: "render", Err: errors.New("failed")}
+	assert.EqualError(t, err, "render dir/file: failed")
+
+	err = &GenerateError{Op: "clean", Err: errors.New("failed")}
+	assert.EqualError(t, err, "clean: failed")
+
+	err = &GenerateError{Path: "main.go", Op: "format", Err: &FormatError{Path: "main.go", Line: 3, Err: errors.New("failed")}}
+	assert.EqualError(t, err, "format: error formatting main.go:3: failed")
+}
+
+func TestFSGenerator_Generate_ContinueOnError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	for _, concurrency := range []int{0, 4} {
+		tmpdir := WritableMapFS{}
+
+		failed := []string{}
+
+		g := &FSGenerator{
+			FS:              tmpdir,
+			ContinueOnError: true,
+			Concurrency:     concurrency,
+			Observer: &ObserverFuncs{OnError: func(path string, _ error) {
+				failed = append(failed, path)
+			}},
+		}
+
+		err := g.Generate(ctx,
+			PlainFile("a", "a"),
+			TemplateFile("b", "{{ index .List 5 }}", map[string]any{"List": []int{}}),
+			Dir("dir",
+				&erroringFile{name: "c", err: fs.ErrInvalid},
+				PlainFile("d", "d"),
+			),
+		)
+
+		var genErr *GenerateError
+		assert.ErrorAs(t, err, &genErr)
+		assert.ErrorIs(t, err, fs.ErrInvalid)
+		assert.Len(t, err.(interface{ Unwrap() []error }).Unwrap(), 2)
+		assert.Equal(t, []string{"b", "dir/c"}, failed)
+
+		assert.Equal(t, "a", string(tmpdir["a"].Data))
+		assert.Equal(t, "d", string(tmpdir["dir/d"].Data))
+		assert.NotContains(t, tmpdir, "b")
+		assert.NotContains(t, tmpdir, "dir/c")
+	}
+}
+
+func TestFSGenerator_Generate_ContinueOnError_Transactional(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmpdir := WritableMapFS{}
+
+	g := &FSGenerator{FS: tmpdir, ContinueOnError: true, Transactional: true}
+
+	err := g.Generate(ctx,
+		&erroringFile{name: "a", err: fs.ErrInvalid},
+		&erroringFile{name: "b", err: fs.ErrPermission},
+		PlainFile("c", "c"),
+	)
+	assert.ErrorIs(t, err, fs.ErrInvalid)
+	assert.ErrorIs(t, err, fs.ErrPermission)
+	assert.Empty(t, tmpdir)
+}
@@ -3,15 +3,154 @@ package drydock
 import (
 	"errors"
 	"io"
+	"io/fs"
+	"maps"
 	"os"
 	"text/template"
 )
 
-func PlainFile(name string, contents string) File {
-	return &plainFile{name: name, contents: []byte(contents)}
+// FileOption configures a file created by [PlainFile], [TemplateFile] or [FileFromTemplate].
+type FileOption func(*fileOptions)
+
+type fileOptions struct {
+	mode         fs.FileMode
+	templateOpts templateOptions
+}
+
+// templateOptions configure how [TemplateFile] and [TemplateNamedFile] parse their templates.
+type templateOptions struct {
+	funcs           template.FuncMap
+	leftDelim       string
+	rightDelim      string
+	missingKeyError bool
+	set             *template.Template
+}
+
+// newTemplate creates an empty template with the options and [Funcs] applied. If a template set
+// is configured, the template is associated with a clone of the set and functions of the set
+// take precedence over [Funcs].
+func (o *templateOptions) newTemplate(name string) (*template.Template, error) {
+	t := template.New(name)
+	funcs := Funcs()
+
+	if o.set != nil {
+		set, err := o.set.Clone()
+		if err != nil {
+			return nil, err
+		}
+
+		funcs, err = undefinedFuncs(o.set, funcs)
+		if err != nil {
+			return nil, err
+		}
+
+		t = set.New(name)
+	}
+
+	if o.leftDelim != "" || o.rightDelim != "" {
+		t.Delims(o.leftDelim, o.rightDelim)
+	}
+
+	t.Funcs(funcs)
+
+	if o.funcs != nil {
+		t.Funcs(o.funcs)
+	}
+
+	if o.missingKeyError {
+		t.Option("missingkey=error")
+	}
+
+	return t, nil
+}
+
+// undefinedFuncs returns the functions of funcs which are not defined in set. Templates don't
+// expose their functions, but parsing a call of an undefined function fails, so every function
+// is parsed in a clone of set.
+func undefinedFuncs(set *template.Template, funcs template.FuncMap) (template.FuncMap, error) {
+	clone, err := set.Clone()
+	if err != nil {
+		return nil, err
+	}
+
+	probe := clone.New("probe").Delims("{{", "}}")
+	undefined := template.FuncMap{}
+
+	for name, fn := range funcs {
+		if _, err := probe.Parse("{{ " + name + " }}"); err != nil {
+			undefined[name] = fn
+		}
+	}
+
+	return undefined, nil
+}
+
+func newFileOptions(opts []FileOption) fileOptions {
+	var o fileOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// Mode implements [FileMode].
+func (o *fileOptions) Mode() fs.FileMode {
+	return o.mode
+}
+
+// WithMode sets the permissions of the generated file, e.g. `0755` for executable scripts.
+func WithMode(mode fs.FileMode) FileOption {
+	return func(o *fileOptions) {
+		o.mode = mode
+	}
+}
+
+// WithFuncs adds funcs to the functions available in the template of a [TemplateFile], in
+// addition to [Funcs]. It can be used multiple times.
+func WithFuncs(funcs template.FuncMap) FileOption {
+	return func(o *fileOptions) {
+		if o.templateOpts.funcs == nil {
+			o.templateOpts.funcs = template.FuncMap{}
+		}
+
+		maps.Copy(o.templateOpts.funcs, funcs)
+	}
+}
+
+// WithDelims sets the action delimiters of the template of a [TemplateFile], e.g. to generate
+// files which contain `{{` and `}}` themselves. An empty delimiter uses the default.
+func WithDelims(left string, right string) FileOption {
+	return func(o *fileOptions) {
+		o.templateOpts.leftDelim = left
+		o.templateOpts.rightDelim = right
+	}
+}
+
+// WithMissingKeyError makes executing the template of a [TemplateFile] fail if a map has no
+// entry for a key, instead of printing `<no value>`.
+func WithMissingKeyError() FileOption {
+	return func(o *fileOptions) {
+		o.templateOpts.missingKeyError = true
+	}
+}
+
+// WithTemplateSet associates the template of a [TemplateFile] with set, so it can use templates
+// defined in set with `{{ template "name" }}`. set is parsed only once and shared between files,
+// it is cloned and not modified. The functions and delimiters of set are used, unless they
+// are overridden with [WithFuncs] or [WithDelims].
+func WithTemplateSet(set *template.Template) FileOption {
+	return func(o *fileOptions) {
+		o.templateOpts.set = set
+	}
+}
+
+func PlainFile(name string, contents string, opts ...FileOption) File {
+	return &plainFile{fileOptions: newFileOptions(opts), name: name, contents: []byte(contents)}
 }
 
 type plainFile struct {
+	fileOptions
 	name     string
 	contents []byte
 }
@@ -30,39 +169,81 @@ func (f *plainFile) WriteTo(w io.Writer) (int64, error) {
 	return int64(n), nil
 }
 
-func TemplateFile(name string, template string, data any) File {
-	return &tmplFile{name: name, template: template, data: data}
+// TemplateFile creates a file whose contents are template executed with data, see [text/template].
+// The template can be configured with [WithFuncs], [WithDelims], [WithMissingKeyError] and
+// [WithTemplateSet]. The template is parsed once when the file is created, errors are reported
+// by [Validate] and [FSGenerator.Generate] before anything is written.
+func TemplateFile(name string, template string, data any, opts ...FileOption) File {
+	return newTmplFile(name, nil, template, data, newFileOptions(opts))
+}
+
+// TemplateNamedFile is like [TemplateFile], but name is a template as well, which is executed
+// with data, e.g. `{{ .Service }}_handler.go`. The name uses the same template options as the
+// contents, but always fails on missing keys. Errors executing the name template and invalid
+// rendered names are reported by [Validate] and [FSGenerator.Generate] before anything is written.
+func TemplateNamedFile(name string, template string, data any, opts ...FileOption) File {
+	o := newFileOptions(opts)
+	rendered, err := renderName(name, data, o.templateOpts)
+
+	return newTmplFile(rendered, err, template, data, o)
+}
+
+func newTmplFile(name string, nameErr error, tmpl string, data any, opts fileOptions) *tmplFile {
+	f := &tmplFile{fileOptions: opts, name: name, nameErr: nameErr, data: data}
+
+	t, err := opts.templateOpts.newTemplate(name)
+	if err == nil {
+		t, err = t.Parse(tmpl)
+	}
+
+	f.template, f.templateErr = t, err
+
+	return f
 }
 
 type tmplFile struct {
-	name     string
-	template string
-	data     any
+	fileOptions
+	name        string
+	nameErr     error
+	template    *template.Template
+	templateErr error
+	data        any
 }
 
 func (f *tmplFile) Name() string {
 	return f.name
 }
 
+func (f *tmplFile) nameError() error {
+	return f.nameErr
+}
+
+func (f *tmplFile) templateError() error {
+	return f.templateErr
+}
+
 // WriteTo implements [io.WriterTo]
 func (f *tmplFile) WriteTo(w io.Writer) (int64, error) {
-	t, err := template.New(f.name).Parse(f.template)
-	if err != nil {
-		return 0, err
+	if f.templateErr != nil {
+		return 0, f.templateErr
 	}
 
-	return 0, t.Execute(w, f.data)
+	cw := &countingWriter{w: w}
+	err := f.template.Execute(cw, f.data)
+
+	return cw.n, err
 }
 
 type Template interface {
 	Execute(w io.Writer, data any) error
 }
 
-func FileFromTemplate(name string, template Template, data any) File {
-	return &fileFromTmpl{name: name, template: template, data: data}
+func FileFromTemplate(name string, template Template, data any, opts ...FileOption) File {
+	return &fileFromTmpl{fileOptions: newFileOptions(opts), name: name, template: template, data: data}
 }
 
 type fileFromTmpl struct {
+	fileOptions
 	name     string
 	template Template
 	data     any
@@ -74,7 +255,10 @@ func (f *fileFromTmpl) Name() string {
 
 // WriteTo implements [io.WriterTo]
 func (f *fileFromTmpl) WriteTo(w io.Writer) (int64, error) {
-	return 0, f.template.Execute(w, f.data)
+	cw := &countingWriter{w: w}
+	err := f.template.Execute(cw, f.data)
+
+	return cw.n, err
 }
 
 func ModifyFile[E any](name string, parse func([]byte, any) error, mod func(*E) ([]byte, error)) File {
@@ -0,0 +1,170 @@
+package drydock
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplateFile_Options(t *testing.T) {
+	partials := template.Must(template.New("partials").Parse(`{{ define "header" }}# {{ .Name }}{{ end }}`))
+	singleQuote := template.FuncMap{"quote": func(s string) string { return "'" + s + "'" }}
+	quotePartials := template.Must(template.New("partials").Funcs(singleQuote).Parse(`{{ define "name" }}{{ quote .Name }}{{ end }}`))
+
+	tt := []struct {
+		name     string
+		template string
+		data     any
+		opts     []FileOption
+		exp      string
+		err      string
+	}{
+		{
+			name:     "Default",
+			template: "{{ .Name }} {{ .Missing }}",
+			data:     map[string]string{"Name": "drydock"},
+			exp:      "drydock <no value>",
+		},
+		{
+			name:     "Funcs",
+			template: "{{ upper .Name }} {{ lower .Name }}",
+			data:     map[string]string{"Name": "Drydock"},
+			opts: []FileOption{
+				WithFuncs(template.FuncMap{"upper": strings.ToUpper}),
+				WithFuncs(template.FuncMap{"lower": strings.ToLower}),
+			},
+			exp: "DRYDOCK drydock",
+		},
+		{
+			name:     "Delims",
+			template: "image: {{ .Values.image }}:[[ .Name ]]",
+			data:     map[string]string{"Name": "v1"},
+			opts:     []FileOption{WithDelims("[[", "]]")},
+			exp:      "image: {{ .Values.image }}:v1",
+		},
+		{
+			name:     "Missing Key Error",
+			template: "{{ .Missing }}",
+			data:     map[string]string{},
+			opts:     []FileOption{WithMissingKeyError()},
+			err:      `template: file:1:3: executing "file" at <.Missing>: map has no entry for key "Missing"`,
+		},
+		{
+			name:     "Template Set",
+			template: `{{ template "header" . }}`,
+			data:     map[string]string{"Name": "drydock"},
+			opts:     []FileOption{WithTemplateSet(partials)},
+			exp:      "# drydock",
+		},
+		{
+			name:     "Template Set Funcs",
+			template: `{{ template "name" . }} {{ quote .Name }} {{ camel .Name }}`,
+			data:     map[string]string{"Name": "dry-dock"},
+			opts:     []FileOption{WithTemplateSet(quotePartials)},
+			exp:      "'dry-dock' 'dry-dock' dryDock",
+		},
+		{
+			name:     "Template Set With Delims",
+			template: `<< template "header" . >> << .Name >>`,
+			data:     map[string]string{"Name": "drydock"},
+			opts:     []FileOption{WithTemplateSet(partials), WithDelims("<<", ">>")},
+			exp:      "# drydock drydock",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var b bytes.Buffer
+
+			_, err := TemplateFile("file", tc.template, tc.data, tc.opts...).(io.WriterTo).WriteTo(&b)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.exp, b.String())
+		})
+	}
+
+	assert.Nil(t, partials.Lookup("file"), "template set must not be modified")
+	assert.Nil(t, quotePartials.Lookup("probe"), "template set must not be modified")
+}
+
+func TestTemplateNamedFile_Options(t *testing.T) {
+	f := TemplateNamedFile("[[ snake .Name ]].go", "", map[string]string{"Name": "user-handler"},
+		WithDelims("[[", "]]"),
+		WithFuncs(template.FuncMap{"snake": func(s string) string { return strings.ReplaceAll(s, "-", "_") }}),
+	)
+
+	assert.Equal(t, "user_handler.go", f.Name())
+	assert.NoError(t, Validate(f))
+}
+
+const benchmarkTemplate = `package {{ goIdent .Name }}
+
+// {{ pascal .Name }} is generated.
+type {{ pascal .Name }} struct {
+{{- range .Fields }}
+	{{ pascal . }} string ` + "`json:{{ quote (camel .) }}`" + `
+{{- end }}
+}
+`
+
+var benchmarkData = map[string]any{"Name": "user-account", "Fields": []string{"id", "email", "created_at"}}
+
+func BenchmarkTemplateFile(b *testing.B) {
+	f := TemplateFile("user.go", benchmarkTemplate, benchmarkData).(io.WriterTo)
+
+	b.ReportAllocs()
+
+	for range b.N {
+		_, err := f.WriteTo(io.Discard)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkTemplateFile_ParsePerWrite parses the template on every write, like TemplateFile did
+// before templates were parsed once when the file is created.
+func BenchmarkTemplateFile_ParsePerWrite(b *testing.B) {
+	b.ReportAllocs()
+
+	for range b.N {
+		t, err := template.New("user.go").Funcs(Funcs()).Parse(benchmarkTemplate)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		err = t.Execute(io.Discard, benchmarkData)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFSGenerator_Generate_TemplateFile(b *testing.B) {
+	ctx, cancel := context.WithCancel(context.Background())
+	b.Cleanup(cancel)
+
+	files := make([]File, 0, 100)
+	for i := range 100 {
+		files = append(files, TemplateFile(fmt.Sprintf("user_%d.go", i), benchmarkTemplate, benchmarkData))
+	}
+
+	b.ReportAllocs()
+
+	for range b.N {
+		err := (&FSGenerator{FS: WritableMapFS{}}).Generate(ctx, files...)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
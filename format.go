@@ -0,0 +1,196 @@
+package drydock
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/scanner"
+	"path"
+)
+
+// FormatterFallback is the key in [FSGenerator.Formatters] of the formatter used for files
+// whose extension has no formatter.
+const FormatterFallback = "*"
+
+// Formatter formats the rendered contents of a file before it is written.
+type Formatter interface {
+	Format(rootFS WritableFS, filename string, src []byte) ([]byte, error)
+}
+
+// FSReadingFormatter can be implemented by a [Formatter] which reads other generated files from
+// rootFS, like [GoImportsFormatter]. With [FSGenerator.Concurrency] files are rendered before the
+// files preceding them are written, so files whose formatter reads rootFS are rendered and
+// formatted when they are written instead.
+type FSReadingFormatter interface {
+	Formatter
+	ReadsRootFS() bool
+}
+
+// FormatterFunc is a function implementing [Formatter].
+type FormatterFunc func(rootFS WritableFS, filename string, src []byte) ([]byte, error)
+
+// Format implements [Formatter].
+func (f FormatterFunc) Format(rootFS WritableFS, filename string, src []byte) ([]byte, error) {
+	return f(rootFS, filename, src)
+}
+
+// DefaultFormatters returns [GoFormatter] for `.go` files, [JSONFormatter] for `.json` files
+// and [WhitespaceFormatter] for all other files.
+func DefaultFormatters() map[string]Formatter {
+	return map[string]Formatter{
+		".go":             GoFormatter{},
+		".json":           JSONFormatter{},
+		FormatterFallback: WhitespaceFormatter{},
+	}
+}
+
+// FormatError is returned when a file could not be formatted. Line is 0 if the
+// position of the error is unknown.
+type FormatError struct {
+	Path string
+	Line int
+	Err  error
+}
+
+func (e *FormatError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("error formatting %s: %v", e.Path, e.Err)
+	}
+
+	return fmt.Sprintf("error formatting %s:%d: %v", e.Path, e.Line, e.Err)
+}
+
+func (e *FormatError) Unwrap() error {
+	return e.Err
+}
+
+// GoFormatter formats Go source code like `gofmt`.
+type GoFormatter struct{}
+
+// Format implements [Formatter].
+func (GoFormatter) Format(_ WritableFS, filename string, src []byte) ([]byte, error) {
+	formatted, err := format.Source(src)
+	if err != nil {
+		return nil, goFormatError(filename, err)
+	}
+
+	return formatted, nil
+}
+
+func goFormatError(filename string, err error) error {
+	var errList scanner.ErrorList
+	if errors.As(err, &errList) && len(errList) != 0 {
+		return &FormatError{Path: filename, Line: errList[0].Pos.Line, Err: errors.New(errList[0].Msg)}
+	}
+
+	return &FormatError{Path: filename, Err: err}
+}
+
+// JSONFormatter indents JSON documents. The default indent is two spaces.
+type JSONFormatter struct {
+	Indent string
+}
+
+// Format implements [Formatter].
+func (f JSONFormatter) Format(_ WritableFS, filename string, src []byte) ([]byte, error) {
+	indent := f.Indent
+	if indent == "" {
+		indent = "  "
+	}
+
+	var b bytes.Buffer
+
+	err := json.Indent(&b, src, "", indent)
+	if err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return nil, &FormatError{Path: filename, Line: lineOf(src, syntaxErr.Offset), Err: err}
+		}
+
+		return nil, &FormatError{Path: filename, Err: err}
+	}
+
+	b.WriteByte('\n')
+
+	return b.Bytes(), nil
+}
+
+func lineOf(src []byte, offset int64) int {
+	if offset > int64(len(src)) {
+		offset = int64(len(src))
+	}
+
+	return bytes.Count(src[:offset], []byte("\n")) + 1
+}
+
+// WhitespaceFormatter removes trailing whitespace from every line as well as trailing
+// empty lines and ends non empty files with a single newline.
+type WhitespaceFormatter struct{}
+
+// Format implements [Formatter].
+func (WhitespaceFormatter) Format(_ WritableFS, _ string, src []byte) ([]byte, error) {
+	lines := bytes.Split(src, []byte("\n"))
+
+	var b bytes.Buffer
+	b.Grow(len(src))
+
+	for _, line := range lines {
+		b.Write(bytes.TrimRight(line, " \t\r"))
+		b.WriteByte('\n')
+	}
+
+	formatted := bytes.TrimRight(b.Bytes(), "\n")
+	if len(formatted) == 0 {
+		return formatted, nil
+	}
+
+	return append(formatted, '\n'), nil
+}
+
+// formatterFor returns the formatter for filename or nil if there is none.
+func (g *FSGenerator) formatterFor(filename string) Formatter {
+	if len(g.Formatters) == 0 {
+		return nil
+	}
+
+	if f, ok := g.Formatters[path.Ext(filename)]; ok {
+		return f
+	}
+
+	return g.Formatters[FormatterFallback]
+}
+
+// format renders and formats file if there is a formatter for it. The returned file contains
+// the formatted contents.
+func (g *FSGenerator) format(ctx context.Context, fsys WritableFS, file *genfile) (*genfile, error) {
+	formatter := g.formatterFor(file.path)
+	if formatter == nil || file.formatted || file.verbatim {
+		return file, nil
+	}
+
+	var b bytes.Buffer
+
+	_, err := file.contents.WriteToFileContext(ctx, fsys, file.path, &b)
+	if err != nil {
+		return nil, &GenerateError{Path: file.path, Op: "render", Err: err}
+	}
+
+	formatted, err := formatter.Format(fsys, file.path, b.Bytes())
+	if err != nil {
+		var formatErr *FormatError
+		if !errors.As(err, &formatErr) {
+			err = &FormatError{Path: file.path, Err: err}
+		}
+
+		return nil, &GenerateError{Path: file.path, Op: "format", Err: err}
+	}
+
+	result := *file
+	result.contents = &renderedContents{formatted}
+	result.formatted = true
+
+	return &result, nil
+}
@@ -0,0 +1,125 @@
+package drydock
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFSGenerator_Generate_Formatters(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	for _, concurrency := range []int{0, 4} {
+		tmpdir := WritableMapFS{}
+
+		g := &FSGenerator{FS: tmpdir, Formatters: DefaultFormatters(), Concurrency: concurrency}
+
+		err := g.Generate(ctx,
+			PlainFile("main.go", "package main\nfunc main() {\n}"),
+			PlainFile("config.json", `{"a":1,"b":[1,2]}`),
+			PlainFile("README.md", "# README  \n\ntext\t\n\n\n"),
+		)
+		assert.NoError(t, err)
+
+		assert.Equal(t, "package main\n\nfunc main() {\n}\n", string(tmpdir["main.go"].Data))
+		assert.Equal(t, "{\n  \"a\": 1,\n  \"b\": [\n    1,\n    2\n  ]\n}\n", string(tmpdir["config.json"].Data))
+		assert.Equal(t, "# README\n\ntext\n", string(tmpdir["README.md"].Data))
+	}
+}
+
+func TestFSGenerator_Generate_Formatters_Errors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tt := []struct {
+		name string
+		file File
+		line int
+	}{
+		{name: "Go", file: PlainFile("main.go", "package main\n\nfunc main() {\n\tx :=\n}\n"), line: 5},
+		{name: "JSON", file: PlainFile("config.json", "{\n  \"a\": 1,\n  \"b\" 2\n}"), line: 3},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpdir := WritableMapFS{}
+
+			g := &FSGenerator{FS: tmpdir, Formatters: DefaultFormatters()}
+
+			err := g.Generate(ctx, tc.file)
+
+			var formatErr *FormatError
+			if assert.True(t, errors.As(err, &formatErr)) {
+				assert.Equal(t, tc.file.Name(), formatErr.Path)
+				assert.Equal(t, tc.line, formatErr.Line)
+			}
+
+			assert.NotContains(t, tmpdir, tc.file.Name())
+		})
+	}
+}
+
+func TestFSGenerator_Generate_Formatters_Custom(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmpdir := WritableMapFS{}
+
+	errFormat := errors.New("can't format")
+
+	g := &FSGenerator{FS: tmpdir, Formatters: map[string]Formatter{
+		".txt": FormatterFunc(func(_ WritableFS, _ string, src []byte) ([]byte, error) {
+			return append([]byte("// generated\n"), src...), nil
+		}),
+		".yml": FormatterFunc(func(_ WritableFS, _ string, _ []byte) ([]byte, error) {
+			return nil, errFormat
+		}),
+	}}
+
+	err := g.Generate(ctx, PlainFile("a.txt", "a"), PlainFile("b.md", "b  "))
+	assert.NoError(t, err)
+	assert.Equal(t, "// generated\na", string(tmpdir["a.txt"].Data))
+	assert.Equal(t, "b  ", string(tmpdir["b.md"].Data))
+
+	err = g.Generate(ctx, PlainFile("c.yml", "c"))
+	assert.ErrorIs(t, err, errFormat)
+	assert.EqualError(t, err, "format: error formatting c.yml: can't format")
+}
+
+func TestWhitespaceFormatter(t *testing.T) {
+	tt := []struct {
+		in  string
+		out string
+	}{
+		{in: "", out: ""},
+		{in: "\n\n", out: ""},
+		{in: "a", out: "a\n"},
+		{in: "a \r\nb\t\n\n", out: "a\nb\n"},
+		{in: "\n  indented", out: "\n  indented\n"},
+	}
+
+	for _, tc := range tt {
+		out, err := WhitespaceFormatter{}.Format(nil, "file", []byte(tc.in))
+		assert.NoError(t, err)
+		assert.Equal(t, tc.out, string(out), "%q", tc.in)
+	}
+}
+
+func TestFSGenerator_Plan_Formatters(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmpdir := WritableMapFS{}
+
+	g := &FSGenerator{FS: tmpdir, Formatters: DefaultFormatters(), SkipUnchanged: true}
+
+	err := g.Generate(ctx, PlainFile("README.md", "# README  \n\n"))
+	assert.NoError(t, err)
+
+	plan, err := g.Plan(ctx, PlainFile("README.md", "# README\t"))
+	assert.NoError(t, err)
+	assert.Empty(t, plan.Operations)
+}
@@ -1,12 +1,16 @@
 package drydock
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"path"
+	"time"
 )
 
 var ErrMissingFS = errors.New("missing FS")
@@ -14,71 +18,246 @@ var ErrMissingFS = errors.New("missing FS")
 type FSGenerator struct {
 	FS WritableFS
 
-	ErrorOnExistingDir  bool
-	CleanDir            bool
+	ErrorOnExistingDir bool
+
+	// CleanDir removes all files and directories in FS before generating. The entries of a
+	// [LazyDirectory] are computed with an empty FS, as everything they could see is removed.
+	CleanDir bool
+
 	ErrorOnExistingFile bool
 
+	// Transactional journals every directory and file created or overwritten, including
+	// the previous contents of overwritten or cleaned files. If generation fails or ctx is
+	// cancelled, all changes are rolled back and the FS is restored to its previous state.
+	Transactional bool
+
+	// Concurrency is the number of files rendered in parallel. Directories are still created
+	// in order and files are written in order after being rendered, so the first error
+	// returned is always the one of the first failing file. Files which modify existing files,
+	// like [ModifyFile], are rendered when they are written. Values <= 1 render sequentially.
+	Concurrency int
+
+	// Observer is notified about created directories, written and skipped files and errors.
+	Observer Observer
+
+	// ConflictResolver decides what happens to existing files which would be overwritten.
+	// Use a [Resolution] to apply the same resolution to every file or [ConflictResolverFunc]
+	// to decide per file. Files which modify existing files, like [ModifyFile], never conflict.
+	// ErrorOnExistingFile takes precedence over the ConflictResolver.
+	ConflictResolver ConflictResolver
+
+	// ManifestPath enables writing a [Manifest] of all generated files to this path inside FS,
+	// usually [DefaultManifestPath]. Existing files which were generated by a previous run and
+	// were not edited since are overwritten, without checking ErrorOnExistingFile or the
+	// ConflictResolver. Files which modify existing files, like [ModifyFile], are not recorded.
+	ManifestPath string
+
+	// Blueprint is recorded in the manifest for every generated file. Files recorded for other
+	// blueprints are kept in the manifest.
+	Blueprint string
+
+	// Prune removes files generated by the previous run of the same Blueprint which are no longer
+	// part of the file tree, as well as directories which are empty afterwards. Files which were
+	// edited since they were generated, and files not recorded in the manifest, are never removed.
+	// Prune requires a manifest and uses [DefaultManifestPath] if ManifestPath is not set.
+	Prune bool
+
+	// ContinueOnError attempts to generate every directory and file even if some of them fail
+	// and returns all errors joined. Errors walking the file tree, cleaning the output dir or
+	// reading the manifest still abort generation immediately.
+	ContinueOnError bool
+
+	// SkipUnchanged compares the rendered contents with existing files and leaves identical
+	// files untouched, reporting them as [ActionUnchanged]. Identical files are never considered
+	// conflicting, even if ErrorOnExistingFile is set.
+	SkipUnchanged bool
+
+	// Formatters format the rendered contents of files before they are written, keyed by file
+	// extension including the dot, e.g. `.go`. The formatter with the key [FormatterFallback] is
+	// used for all other files. See [DefaultFormatters] for the built-in formatters.
+	Formatters map[string]Formatter
+
+	fsys        WritableFS
 	createdDirs map[string]struct{}
+	journal     *journal
+	report      *Report
+	manifests   *manifests
+	errs        []error
 }
 
 var createdDir = struct{}{}
 
 type genfile struct {
-	path      string
-	contents  WriterToFile
-	isNewFile bool
+	name        string
+	nameErr     error
+	templateErr error
+	verbatim    bool
+	modTime     time.Time
+	path        string
+	contents    ContextWriterToFile
+	isNewFile   bool
+	renderTime  time.Duration
+	noManifest  bool
+	mode        fs.FileMode
+	symlink     string
+	formatted   bool
 }
 
 type gendir struct {
-	path string
+	name    string
+	nameErr error
+	path    string
+	mode    fs.FileMode
 }
 
+const (
+	defaultFileMode fs.FileMode = 0644
+	defaultDirMode  fs.FileMode = 0755
+)
+
 func (g *FSGenerator) Generate(ctx context.Context, files ...File) error {
+	_, err := g.GenerateWithReport(ctx, files...)
+	return err
+}
+
+// GenerateWithReport is like [FSGenerator.Generate] but also returns a [Report] of every path
+// it touched. If an error occurs, the report contains all paths processed up to the error.
+func (g *FSGenerator) GenerateWithReport(ctx context.Context, files ...File) (*Report, error) {
 	if g.FS == nil {
-		return ErrMissingFS
+		return nil, ErrMissingFS
 	}
 
+	g.report = &Report{}
+
+	err := g.generateAll(ctx, files)
+
+	return g.report, err
+}
+
+func (g *FSGenerator) generateAll(ctx context.Context, files []File) (err error) {
+	g.fsys = g.FS
 	g.createdDirs = map[string]struct{}{}
+	g.journal = nil
+	g.errs = nil
+
+	if g.Concurrency > 1 {
+		g.fsys = newSyncFS(g.FS)
+	}
+
+	if g.Transactional {
+		g.journal = &journal{}
+		defer func() {
+			if err != nil {
+				err = errors.Join(err, g.journal.rollback(g.fsys))
+			}
+		}()
+	}
+
+	defer func() {
+		if len(g.errs) != 0 {
+			err = errors.Join(append(g.errs, err)...)
+		}
+	}()
+
+	gendirs, genfiles, err := g.collect(ctx, g.lazyRootFS(g.fsys), files)
+	if err != nil {
+		return g.fail("walk", "", err)
+	}
+
+	err = validate(gendirs, genfiles)
+	if err != nil {
+		return g.fail("validate", "", err)
+	}
 
 	if g.CleanDir {
-		err := cleanDir(g.FS, ".")
+		err = g.cleanDir()
 		if err != nil {
-			return err
+			return g.fail("clean", "", err)
 		}
 	}
 
-	gendirs := make([]*gendir, 0, len(files))
-	genfiles := make([]*genfile, 0, len(files))
+	err = g.loadManifests()
+	if err != nil {
+		return g.fail("manifest", g.manifestPath(), err)
+	}
 
-	for _, f := range files {
-		dirs, files, err := g.generate(ctx, "", f)
+	for _, d := range gendirs {
+		err = g.generateRealDir(d.path, d.mode)
 		if err != nil {
-			return err
+			err = g.failed("mkdir", d.path, err)
+			if err != nil {
+				return err
+			}
 		}
+	}
 
-		gendirs = append(gendirs, dirs...)
-		genfiles = append(genfiles, files...)
+	err = g.generateRealFiles(ctx, genfiles)
+	if err != nil {
+		return err
 	}
 
-	for _, d := range gendirs {
-		err := g.generateRealDir(d.path)
+	err = g.prune(gendirs, genfiles)
+	if err != nil {
+		return err
+	}
+
+	err = g.writeManifest(ctx)
+	if err != nil {
+		return g.fail("manifest", g.manifestPath(), err)
+	}
+
+	return nil
+}
+
+func (g *FSGenerator) cleanDir() error {
+	if g.journal != nil {
+		err := g.journal.snapshotRemoval(g.fsys, ".")
 		if err != nil {
-			return err
+			return fmt.Errorf("%w: %w", ErrCleaningOutputDir, err)
 		}
 	}
 
-	for _, f := range genfiles {
-		err := g.generateRealFile(f)
+	return cleanDir(g.fsys, ".")
+}
+
+// lazyRootFS returns the FS the entries of a [LazyDirectory] are computed with. With
+// [FSGenerator.CleanDir] fsys is cleaned before anything is written, so the FS is empty.
+func (g *FSGenerator) lazyRootFS(fsys WritableFS) WritableFS {
+	if g.CleanDir {
+		return WritableMapFS{}
+	}
+
+	return fsys
+}
+
+// collect walks the file tree. Entries of a [LazyDirectory] are computed with rootFS, they are
+// skipped if rootFS is nil.
+func (g *FSGenerator) collect(ctx context.Context, rootFS WritableFS, files []File) ([]*gendir, []*genfile, error) {
+	gendirs := make([]*gendir, 0, len(files))
+	genfiles := make([]*genfile, 0, len(files))
+
+	for _, f := range Flatten(files...) {
+		dirs, files, err := g.generate(ctx, rootFS, "", f)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
+
+		gendirs = append(gendirs, dirs...)
+		genfiles = append(genfiles, files...)
 	}
 
-	return nil
+	return gendirs, genfiles, nil
 }
 
-func (g *FSGenerator) generateRealDir(dir string) error {
-	err := g.FS.Mkdir(dir, 0755)
+func (g *FSGenerator) generateRealDir(dir string, mode fs.FileMode) error {
+	start := time.Now()
+
+	perm := mode
+	if perm == 0 {
+		perm = defaultDirMode
+	}
+
+	err := g.fsys.Mkdir(dir, perm)
 	if err != nil {
 		if !errors.Is(err, fs.ErrExist) {
 			return err
@@ -88,6 +267,27 @@ func (g *FSGenerator) generateRealDir(dir string) error {
 		if g.ErrorOnExistingDir && !created {
 			return err
 		}
+
+		if !created {
+			g.report.add(ReportEntry{Path: dir, IsDir: true, Action: ActionUnchanged, Duration: time.Since(start)})
+		}
+	}
+
+	if err == nil {
+		if g.journal != nil {
+			g.journal.created(dir, true)
+		}
+
+		// Mkdir is subject to the umask, explicit modes are applied exactly
+		if mode != 0 {
+			err = chmod(g.fsys, dir, mode)
+			if err != nil {
+				return err
+			}
+		}
+
+		g.report.add(ReportEntry{Path: dir, IsDir: true, Action: ActionCreated, Duration: time.Since(start)})
+		g.observer().DirCreated(dir)
 	}
 
 	g.createdDirs[dir] = createdDir
@@ -95,21 +295,165 @@ func (g *FSGenerator) generateRealDir(dir string) error {
 	return nil
 }
 
-func (g *FSGenerator) generateRealFile(file *genfile) error {
-	if g.ErrorOnExistingFile && file.isNewFile {
-		stat, statErr := statFile(g.FS, file.path)
-		if statErr != nil {
-			if !errors.Is(statErr, fs.ErrNotExist) {
-				return statErr
-			}
+func (g *FSGenerator) generateRealFile(ctx context.Context, file *genfile) error {
+	start := time.Now()
+
+	if file.symlink != "" {
+		return g.generateSymlink(ctx, file)
+	}
+
+	if file.contents == nil {
+		g.skipped(file.path)
+		return nil
+	}
+
+	file, err := g.format(ctx, g.fsys, file)
+	if err != nil {
+		return err
+	}
+
+	existed, err := fileExists(g.fsys, file.path)
+	if err != nil {
+		return err
+	}
+
+	if g.SkipUnchanged && existed {
+		var unchanged bool
+
+		file, unchanged, err = g.checkUnchanged(ctx, file, start)
+		if err != nil || unchanged {
+			return err
 		}
+	}
 
-		if stat != nil {
-			return fmt.Errorf("file already exits %s: %w", file.path, fs.ErrExist)
+	if file.isNewFile && existed {
+		file, existed, err = g.checkExisting(ctx, file)
+		if err != nil || file == nil {
+			return err
 		}
 	}
 
-	tmpfile, err := g.FS.CreateTemp("", path.Base(file.path))
+	return g.writeRealFile(ctx, file, existed, start)
+}
+
+// checkUnchanged renders file and compares it and its explicit mode and modification time with
+// the existing file. The returned file contains the rendered contents, so it's not rendered twice.
+func (g *FSGenerator) checkUnchanged(ctx context.Context, file *genfile, start time.Time) (*genfile, bool, error) {
+	rendered, hash, err := g.compareContents(ctx, file)
+	if err != nil {
+		return nil, false, err
+	}
+
+	changed := hash == ""
+	if !changed {
+		changed, err = attributesChanged(g.FS, file)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	if changed {
+		return rendered, false, nil
+	}
+
+	if file.isNewFile && !file.noManifest {
+		g.recordFile(file.path, hash)
+	}
+
+	g.report.add(ReportEntry{Path: file.path, Action: ActionUnchanged, Duration: file.renderTime + time.Since(start)})
+	g.observer().FileSkipped(file.path)
+
+	return file, true, nil
+}
+
+// compareContents compares the contents of file with the existing file. If they are equal, the
+// hash of the contents is returned. The returned file contains the rendered contents, unless file
+// is copied verbatim, which is compared without loading it into memory.
+func (g *FSGenerator) compareContents(ctx context.Context, file *genfile) (*genfile, string, error) {
+	if file.verbatim {
+		equal, hash, err := equalContents(ctx, g.fsys, file)
+		if err != nil || !equal {
+			return file, "", err
+		}
+
+		return file, hash, nil
+	}
+
+	existing, err := g.fsys.ReadFile(file.path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var b bytes.Buffer
+
+	_, err = file.contents.WriteToFileContext(ctx, g.fsys, file.path, &b)
+	if err != nil {
+		return nil, "", &GenerateError{Path: file.path, Op: "render", Err: err}
+	}
+
+	rendered := *file
+	rendered.contents = &renderedContents{b.Bytes()}
+
+	if !bytes.Equal(existing, b.Bytes()) {
+		return &rendered, "", nil
+	}
+
+	return &rendered, hashContents(existing), nil
+}
+
+// attributesChanged reports whether the explicit mode or modification time of f differ from the
+// existing file. Attributes which fsys can't change are ignored.
+func attributesChanged(fsys WritableFS, f *genfile) (bool, error) {
+	_, canChmod := fsys.(ChmodFS)
+	_, canChtimes := fsys.(ChtimesFS)
+
+	checkMode := f.mode != 0 && canChmod
+	checkModTime := !f.modTime.IsZero() && canChtimes
+
+	if !checkMode && !checkModTime {
+		return false, nil
+	}
+
+	stat, err := statFile(fsys, f.path)
+	if err != nil {
+		return false, err
+	}
+
+	if checkMode && stat.Mode().Perm() != f.mode.Perm() {
+		return true, nil
+	}
+
+	return checkModTime && !stat.ModTime().Equal(f.modTime), nil
+}
+
+// checkExisting returns the file which should be written instead of the existing file
+// and whether it exists. A nil file means the file was skipped.
+func (g *FSGenerator) checkExisting(ctx context.Context, file *genfile) (*genfile, bool, error) {
+	unmodified, err := g.unmodified(file.path)
+	if err != nil || unmodified {
+		return file, true, err
+	}
+
+	if g.ErrorOnExistingFile {
+		return nil, false, fmt.Errorf("file already exits %s: %w", file.path, fs.ErrExist)
+	}
+
+	if g.ConflictResolver != nil {
+		return g.resolveConflict(ctx, file)
+	}
+
+	return file, true, nil
+}
+
+func (g *FSGenerator) skipped(path string) {
+	g.report.add(ReportEntry{Path: path, Action: ActionSkipped})
+	g.observer().FileSkipped(path)
+}
+
+func (g *FSGenerator) writeRealFile(ctx context.Context, file *genfile, existed bool, start time.Time) (err error) {
+	g.observer().FileWriting(file.path)
+
+	tmpfile, err := g.fsys.CreateTemp("", path.Base(file.path))
 	if err != nil {
 		return err
 	}
@@ -120,24 +464,132 @@ func (g *FSGenerator) generateRealFile(file *genfile) error {
 		)
 
 		if err != nil {
-			err = errors.Join(err, g.FS.Remove(tmpfile.Name()))
+			err = errors.Join(err, removeTemp(g.fsys, tmpfile))
 		}
 	}()
 
-	_, err = file.contents.WriteToFile(g.FS, file.path, tmpfile)
+	var w io.Writer = tmpfile
+
+	hash := sha256.New()
+	if g.manifests != nil && file.isNewFile && !file.noManifest {
+		w = io.MultiWriter(tmpfile, hash)
+	}
+
+	cw := &countingWriter{w: w}
+
+	_, err = file.contents.WriteToFileContext(ctx, g.fsys, file.path, cw)
 	if err != nil {
-		return err
+		if cw.err != nil && errors.Is(err, cw.err) {
+			return err
+		}
+
+		return &GenerateError{Path: file.path, Op: "render", Err: err}
 	}
 
-	err = g.FS.Rename(tmpfile.Name(), file.path)
+	var backup []byte
+	var prevMode fs.FileMode
+	if existed {
+		backup, prevMode, err = g.readBackup(file.path)
+		if err != nil {
+			return err
+		}
+	}
+
+	// renaming replaces an existing symlink, even a dangling one, instead of writing to its target
+	var prevTarget string
+	var replacesSymlink bool
+	if g.journal != nil {
+		prevTarget, replacesSymlink, err = readlink(g.fsys, file.path)
+		if err != nil && !errors.Is(err, errors.ErrUnsupported) {
+			return err
+		}
+	}
+
+	err = g.fsys.Rename(tmpfile.Name(), file.path)
 	if err != nil {
 		return fmt.Errorf("error moving tempfile to real file %s: %w", file.path, err)
 	}
 
+	if g.journal != nil {
+		switch {
+		case replacesSymlink:
+			g.journal.removedSymlink(file.path, prevTarget)
+			g.journal.created(file.path, false)
+		case existed:
+			g.journal.overwritten(file.path, backup, prevMode)
+		default:
+			g.journal.created(file.path, false)
+		}
+	}
+
+	err = chmod(g.fsys, file.path, fileMode(file.mode, prevMode))
+	if err != nil {
+		return err
+	}
+
+	if !file.modTime.IsZero() {
+		err = chtimes(g.fsys, file.path, file.modTime)
+		if err != nil {
+			return err
+		}
+	}
+
+	if g.manifests != nil && file.isNewFile && !file.noManifest {
+		g.recordFile(file.path, hex.EncodeToString(hash.Sum(nil)))
+	}
+
+	entry := ReportEntry{
+		Path:     file.path,
+		Action:   fileAction(existed, file.isNewFile),
+		Bytes:    cw.n,
+		Duration: file.renderTime + time.Since(start),
+	}
+
+	g.report.add(entry)
+	g.observer().FileWritten(entry)
+
 	return nil
 }
 
-func (g *FSGenerator) generate(ctx context.Context, parentDir string, file File) ([]*gendir, []*genfile, error) {
+// readBackup returns the mode of the existing file p and its contents, if they are needed
+// to roll back.
+func (g *FSGenerator) readBackup(p string) ([]byte, fs.FileMode, error) {
+	stat, err := statFile(g.fsys, p)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if g.journal == nil {
+		return nil, stat.Mode().Perm(), nil
+	}
+
+	backup, err := g.fsys.ReadFile(p)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return backup, stat.Mode().Perm(), nil
+}
+
+// fileMode returns the mode a file should have after being written. Files without an
+// explicit mode keep the mode of the file they replace or get the default mode.
+func fileMode(mode fs.FileMode, prevMode fs.FileMode) fs.FileMode {
+	switch {
+	case mode != 0:
+		return mode
+	case prevMode != 0:
+		return prevMode
+	default:
+		return defaultFileMode
+	}
+}
+
+func (g *FSGenerator) generate(
+	ctx context.Context,
+	rootFS WritableFS,
+	parentDir string,
+	file File,
+) ([]*gendir, []*genfile, error) {
 	select {
 	case <-ctx.Done():
 		return nil, nil, ctx.Err()
@@ -145,44 +597,79 @@ func (g *FSGenerator) generate(ctx context.Context, parentDir string, file File)
 	}
 
 	if dir, ok := file.(Directory); ok {
-		return g.generateDir(ctx, parentDir, dir)
+		return g.generateDir(ctx, rootFS, parentDir, dir)
+	}
+
+	gf := &genfile{
+		name:        file.Name(),
+		nameErr:     nameErrorOf(file),
+		templateErr: templateErrorOf(file),
+		verbatim:    isVerbatim(file),
+		path:        joinPath(parentDir, file.Name()),
+		isNewFile:   true,
+		mode:        modeOf(file),
+		modTime:     modTimeOf(file),
 	}
 
-	isNewFile := true
 	if f, ok := file.(IsNewFile); ok {
-		isNewFile = f.IsNewFile()
+		gf.isNewFile = f.IsNewFile()
 	}
 
-	if wt, ok := file.(WriterToFile); ok {
-		return nil, []*genfile{{path: path.Join(parentDir, file.Name()), contents: wt, isNewFile: isNewFile}}, nil
+	if link, ok := file.(SymlinkFile); ok {
+		gf.symlink = link.Target()
+	} else if wt, ok := file.(ContextWriterToFile); ok {
+		gf.contents = wt
+	} else if wt, ok := file.(WriterToFile); ok {
+		gf.contents = &writerToFileAdapter{wt}
+	} else if wt, ok := file.(io.WriterTo); ok {
+		gf.contents = &writerToAdapter{wt}
 	}
 
-	if wt, ok := file.(io.WriterTo); ok {
-		return nil, []*genfile{{path: path.Join(parentDir, file.Name()), contents: &writerToAdapter{wt}, isNewFile: isNewFile}}, nil
+	return nil, []*genfile{gf}, nil
+}
+
+func modeOf(file File) fs.FileMode {
+	if f, ok := file.(FileMode); ok {
+		return f.Mode()
 	}
 
-	return nil, nil, nil
+	return 0
 }
 
-func (g *FSGenerator) generateDir(ctx context.Context, parentDir string, dir Directory) ([]*gendir, []*genfile, error) {
+func modTimeOf(file File) time.Time {
+	if f, ok := file.(FileModTime); ok {
+		return f.ModTime()
+	}
+
+	return time.Time{}
+}
+
+func (g *FSGenerator) generateDir(
+	ctx context.Context,
+	rootFS WritableFS,
+	parentDir string,
+	dir Directory,
+) ([]*gendir, []*genfile, error) {
 	select {
 	case <-ctx.Done():
 		return nil, nil, ctx.Err()
 	default:
 	}
 
-	dirpath := path.Join(parentDir, dir.Name())
+	dirpath := joinPath(parentDir, dir.Name())
 
-	entries, err := dir.Entries()
+	entries, err := dirEntries(ctx, rootFS, dirpath, dir)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	gendirs := []*gendir{{path: dirpath}}
+	entries = Flatten(entries...)
+
+	gendirs := []*gendir{{name: dir.Name(), nameErr: nameErrorOf(dir), path: dirpath, mode: modeOf(dir)}}
 	genfiles := make([]*genfile, 0, len(entries))
 
 	for _, f := range entries {
-		dirs, files, err := g.generate(ctx, dirpath, f)
+		dirs, files, err := g.generate(ctx, rootFS, dirpath, f)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -194,6 +681,29 @@ func (g *FSGenerator) generateDir(ctx context.Context, parentDir string, dir Dir
 	return gendirs, genfiles, nil
 }
 
+// joinPath joins parentDir and name without cleaning the result, so invalid names are caught
+// by validation instead of silently changing the path.
+func joinPath(parentDir string, name string) string {
+	if parentDir == "" {
+		return name
+	}
+
+	return parentDir + "/" + name
+}
+
+func fileExists(rootFS fs.FS, name string) (bool, error) {
+	_, err := statFile(rootFS, name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
 func statFile(rootFS fs.FS, name string) (fs.FileInfo, error) {
 	if statFS, ok := rootFS.(fs.StatFS); ok {
 		return statFS.Stat(name)
@@ -5,11 +5,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path"
 	"testing"
+	"testing/fstest"
 	"text/template"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -337,3 +340,236 @@ func readDir(wmfs WritableMapFS, p ...string) []fs.FileInfo {
 
 	return entries
 }
+
+type erroringFile struct {
+	name string
+	err  error
+}
+
+func (f *erroringFile) Name() string {
+	return f.name
+}
+
+func (f *erroringFile) WriteTo(w io.Writer) (int64, error) {
+	return 0, f.err
+}
+
+func TestFSGenerator_Generate_SkipUnchanged(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmpdir := WritableMapFS{}
+
+	g := &FSGenerator{FS: tmpdir, SkipUnchanged: true, ErrorOnExistingFile: true}
+
+	err := g.Generate(ctx, PlainFile("unchanged.txt", "contents"), PlainFile("changed.txt", "contents"))
+	assert.NoError(t, err)
+
+	unchanged := tmpdir["unchanged.txt"]
+
+	plan, err := g.Plan(ctx, PlainFile("unchanged.txt", "contents"))
+	assert.NoError(t, err)
+	assert.Empty(t, plan.Operations)
+
+	report, err := g.GenerateWithReport(ctx, PlainFile("unchanged.txt", "contents"))
+	assert.NoError(t, err)
+	assert.Equal(t, []ReportEntry{{Path: "unchanged.txt", Action: ActionUnchanged}}, clearDurations(report.Entries))
+	assert.Same(t, unchanged, tmpdir["unchanged.txt"])
+
+	_, err = g.GenerateWithReport(ctx, PlainFile("changed.txt", "new contents"))
+	assert.ErrorIs(t, err, fs.ErrExist)
+
+	g.ErrorOnExistingFile = false
+
+	report, err = g.GenerateWithReport(ctx, PlainFile("changed.txt", "new contents"))
+	assert.NoError(t, err)
+	assert.Equal(t, []ReportEntry{{Path: "changed.txt", Action: ActionOverwritten, Bytes: 12}}, clearDurations(report.Entries))
+}
+
+func TestFSGenerator_Generate_SkipUnchanged_Mode(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmpdir := WritableMapFS{
+		"build.sh":  &fstest.MapFile{Data: []byte("#!/bin/sh"), Mode: 0644},
+		"README.md": &fstest.MapFile{Data: []byte("readme"), Mode: 0600},
+	}
+
+	g := &FSGenerator{FS: tmpdir, SkipUnchanged: true}
+
+	plan, err := g.Plan(ctx, PlainFile("build.sh", "#!/bin/sh", WithMode(0755)), PlainFile("README.md", "readme"))
+	assert.NoError(t, err)
+	assert.Equal(t, []Operation{{Op: OpOverwrite, Path: "build.sh"}}, plan.Operations)
+
+	report, err := g.GenerateWithReport(ctx, PlainFile("build.sh", "#!/bin/sh", WithMode(0755)), PlainFile("README.md", "readme"))
+	assert.NoError(t, err)
+	assert.Equal(t, []ReportEntry{
+		{Path: "build.sh", Action: ActionOverwritten, Bytes: 9},
+		{Path: "README.md", Action: ActionUnchanged},
+	}, clearDurations(report.Entries))
+	assert.Equal(t, fs.FileMode(0755), tmpdir["build.sh"].Mode)
+	assert.Equal(t, fs.FileMode(0600), tmpdir["README.md"].Mode)
+}
+
+func clearDurations(entries []ReportEntry) []ReportEntry {
+	for i := range entries {
+		entries[i].Duration = 0
+	}
+
+	return entries
+}
+
+func TestFSGenerator_Generate_FileModes(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmpdir := WritableMapFS{
+		"private.txt": &fstest.MapFile{Data: []byte("old"), Mode: 0600},
+	}
+
+	g := &FSGenerator{FS: tmpdir, Concurrency: 2}
+
+	err := g.Generate(ctx,
+		PlainFile("README.md", "readme"),
+		PlainFile("private.txt", "new"),
+		DirMode("scripts", 0700,
+			TemplateFile("build.sh", "#!/bin/sh", nil, WithMode(0755)),
+		),
+	)
+	assert.NoError(t, err)
+
+	assert.Equal(t, fs.FileMode(0644), tmpdir["README.md"].Mode)
+	assert.Equal(t, fs.FileMode(0600), tmpdir["private.txt"].Mode)
+	assert.Equal(t, 0700|fs.ModeDir, tmpdir["scripts"].Mode)
+	assert.Equal(t, fs.FileMode(0755), tmpdir["scripts/build.sh"].Mode)
+}
+
+type contextFile struct {
+	name string
+}
+
+func (f *contextFile) Name() string {
+	return f.name
+}
+
+func (f *contextFile) WriteToFileContext(ctx context.Context, _ WritableFS, filename string, w io.Writer) (int64, error) {
+	if v, ok := ctx.Value(contextFileKey{}).(string); ok {
+		n, err := io.WriteString(w, filename+": "+v)
+		return int64(n), err
+	}
+
+	<-ctx.Done()
+
+	return 0, ctx.Err()
+}
+
+type contextFileKey struct{}
+
+// chunkedFile writes its chunks one by one and calls onChunk after each of them.
+type chunkedFile struct {
+	name    string
+	chunks  []string
+	onChunk func()
+}
+
+func (f *chunkedFile) Name() string {
+	return f.name
+}
+
+func (f *chunkedFile) WriteToFile(_ WritableFS, _ string, w io.Writer) (int64, error) {
+	var written int64
+
+	for _, chunk := range f.chunks {
+		n, err := io.WriteString(w, chunk)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+
+		f.onChunk()
+	}
+
+	return written, nil
+}
+
+func TestFSGenerator_Generate_ContextWriterToFile(t *testing.T) {
+	t.Run("Context Is Passed", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.WithValue(context.Background(), contextFileKey{}, "value"))
+		t.Cleanup(cancel)
+
+		tmpdir := WritableMapFS{}
+
+		err := (&FSGenerator{FS: tmpdir}).Generate(ctx, Dir("dir", &contextFile{name: "file"}))
+		assert.NoError(t, err)
+		assert.Equal(t, "dir/file: value", string(tmpdir["dir/file"].Data))
+	})
+
+	t.Run("Cancel Long Running Writer", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		t.Cleanup(cancel)
+
+		tmpdir := WritableMapFS{}
+
+		err := (&FSGenerator{FS: tmpdir}).Generate(ctx, &contextFile{name: "file"}, PlainFile("other", "other"))
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.NotContains(t, tmpdir, "file")
+		assert.NotContains(t, tmpdir, "other")
+	})
+
+	t.Run("Cancel WriterToFile Between Writes", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+
+		tmpdir := WritableMapFS{}
+
+		chunks := 0
+
+		err := (&FSGenerator{FS: tmpdir}).Generate(ctx, &chunkedFile{
+			name:   "file",
+			chunks: []string{"a", "b", "c"},
+			onChunk: func() {
+				chunks++
+				cancel()
+			},
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, chunks)
+		assert.NotContains(t, tmpdir, "file")
+	})
+
+	t.Run("Cancel Between Files", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+
+		tmpdir := WritableMapFS{}
+
+		err := (&FSGenerator{FS: tmpdir}).Generate(ctx,
+			&chunkedFile{name: "a", chunks: []string{"a"}, onChunk: cancel},
+			PlainFile("b", "b"),
+		)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, "a", string(tmpdir["a"].Data))
+		assert.NotContains(t, tmpdir, "b")
+	})
+}
+
+func TestFSGenerator_Generate_TemplateNamed(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmpdir := WritableMapFS{}
+
+	blueprint := func(service string) File {
+		data := map[string]string{"Service": service}
+
+		return TemplateNamedDir("{{ .Service }}",
+			data,
+			TemplateNamedFile("{{ .Service }}_handler.go", "package {{ .Service }}\n", data),
+		)
+	}
+
+	err := (&FSGenerator{FS: tmpdir}).Generate(ctx, blueprint("users"), blueprint("posts"))
+	assert.NoError(t, err)
+	assert.Equal(t, "package users\n", string(tmpdir["users/users_handler.go"].Data))
+	assert.Equal(t, "package posts\n", string(tmpdir["posts/posts_handler.go"].Data))
+}
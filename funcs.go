@@ -0,0 +1,271 @@
+package drydock
+
+import (
+	"fmt"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+)
+
+// Funcs returns the functions available in every [TemplateFile] and in the names of
+// [TemplateNamedFile] and [TemplateNamedDir]. Functions added with [WithFuncs] and functions of
+// a [WithTemplateSet] take precedence.
+// Use Funcs for templates parsed by hand, e.g. for [FileFromTemplate] or [WithTemplateSet].
+//
+//   - camel, pascal, snake, kebab, screaming: convert "HTTP server-name" to "httpServerName",
+//     "HttpServerName", "http_server_name", "http-server-name" and "HTTP_SERVER_NAME".
+//   - goIdent: turns a string into a valid Go identifier, e.g. "my-pkg" into "my_pkg".
+//   - pluralize: the English plural of a noun, e.g. "category" to "categories".
+//   - indent, nindent: indent every line by n spaces, nindent starts with a newline.
+//   - quote: a double quoted Go string literal.
+//   - join, split: like [strings.Join] and [strings.Split] with the separator first, for pipelines.
+//   - default: the default value if the value is empty, e.g. `{{ .Port | default 8080 }}`.
+//   - year: the current year, e.g. for license headers.
+func Funcs() template.FuncMap {
+	return template.FuncMap{
+		"camel":     camelCase,
+		"pascal":    pascalCase,
+		"snake":     snakeCase,
+		"kebab":     kebabCase,
+		"screaming": screamingCase,
+		"goIdent":   goIdent,
+		"pluralize": pluralize,
+		"indent":    indent,
+		"nindent":   nindent,
+		"quote":     quote,
+		"join":      join,
+		"split":     split,
+		"default":   defaultValue,
+		"year":      year,
+	}
+}
+
+// words splits s into words at non alphanumeric characters and at changes of case,
+// keeping initialisms together: "HTTPServer-name" becomes "HTTP", "Server" and "name".
+func words(s string) []string {
+	var result []string
+	var word []rune
+
+	runes := []rune(s)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(word) != 0 {
+				result = append(result, string(word))
+				word = nil
+			}
+
+			continue
+		}
+
+		if len(word) != 0 && unicode.IsUpper(r) {
+			prev := word[len(word)-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+				result = append(result, string(word))
+				word = nil
+			}
+		}
+
+		word = append(word, r)
+	}
+
+	if len(word) != 0 {
+		result = append(result, string(word))
+	}
+
+	return result
+}
+
+func capitalize(word string) string {
+	runes := []rune(strings.ToLower(word))
+	runes[0] = unicode.ToUpper(runes[0])
+
+	return string(runes)
+}
+
+func camelCase(s string) string {
+	var b strings.Builder
+
+	for i, word := range words(s) {
+		if i == 0 {
+			b.WriteString(strings.ToLower(word))
+			continue
+		}
+
+		b.WriteString(capitalize(word))
+	}
+
+	return b.String()
+}
+
+func pascalCase(s string) string {
+	var b strings.Builder
+
+	for _, word := range words(s) {
+		b.WriteString(capitalize(word))
+	}
+
+	return b.String()
+}
+
+func snakeCase(s string) string {
+	return strings.ToLower(strings.Join(words(s), "_"))
+}
+
+func kebabCase(s string) string {
+	return strings.ToLower(strings.Join(words(s), "-"))
+}
+
+func screamingCase(s string) string {
+	return strings.ToUpper(strings.Join(words(s), "_"))
+}
+
+// goIdent replaces every character which is not allowed in a Go identifier with an underscore.
+// Identifiers starting with a digit are prefixed and keywords are suffixed with an underscore.
+func goIdent(s string) string {
+	ident := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return r
+		}
+
+		return '_'
+	}, s)
+
+	switch {
+	case ident == "":
+		return "_"
+	case unicode.IsDigit([]rune(ident)[0]):
+		return "_" + ident
+	case token.IsKeyword(ident):
+		return ident + "_"
+	}
+
+	return ident
+}
+
+var irregularPlurals = map[string]string{
+	"child":  "children",
+	"foot":   "feet",
+	"goose":  "geese",
+	"man":    "men",
+	"mouse":  "mice",
+	"person": "people",
+	"tooth":  "teeth",
+	"woman":  "women",
+}
+
+var uncountables = map[string]bool{
+	"data":        true,
+	"equipment":   true,
+	"information": true,
+	"metadata":    true,
+	"news":        true,
+	"series":      true,
+	"sheep":       true,
+	"species":     true,
+}
+
+// pluralize returns the English plural of noun. Irregular plurals keep the case of the
+// first letter of noun.
+func pluralize(noun string) string {
+	lower := strings.ToLower(noun)
+
+	if noun == "" || uncountables[lower] {
+		return noun
+	}
+
+	if plural, ok := irregularPlurals[lower]; ok {
+		if unicode.IsUpper([]rune(noun)[0]) {
+			return capitalize(plural)
+		}
+
+		return plural
+	}
+
+	switch {
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "z"),
+		strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		return noun + "es"
+	case strings.HasSuffix(lower, "y") && len(lower) > 1 && !strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		return noun[:len(noun)-1] + "ies"
+	}
+
+	return noun + "s"
+}
+
+// indent indents every non-empty line of s by n spaces.
+func indent(n int, s string) string {
+	pad := strings.Repeat(" ", n)
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = pad + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// nindent is like indent, but starts with a newline, so it can be used at the end of a line.
+func nindent(n int, s string) string {
+	return "\n" + indent(n, s)
+}
+
+func quote(v any) string {
+	return strconv.Quote(fmt.Sprint(v))
+}
+
+// join joins the elements of the slice elems, which are formatted with [fmt.Sprint].
+func join(sep string, elems any) (string, error) {
+	if s, ok := elems.([]string); ok {
+		return strings.Join(s, sep), nil
+	}
+
+	v := reflect.ValueOf(elems)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return "", fmt.Errorf("join: can't join %T", elems)
+	}
+
+	s := make([]string, v.Len())
+	for i := range s {
+		s[i] = fmt.Sprint(v.Index(i).Interface())
+	}
+
+	return strings.Join(s, sep), nil
+}
+
+func split(sep string, s string) []string {
+	return strings.Split(s, sep)
+}
+
+// defaultValue returns def if v is nil, the zero value or an empty slice, map or string.
+func defaultValue(def any, v any) any {
+	if v == nil {
+		return def
+	}
+
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.String:
+		if rv.Len() == 0 {
+			return def
+		}
+	default:
+		if rv.IsZero() {
+			return def
+		}
+	}
+
+	return v
+}
+
+func year() int {
+	return time.Now().Year()
+}
@@ -0,0 +1,185 @@
+package drydock
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuncs_Cases(t *testing.T) {
+	tt := []struct {
+		input     string
+		camel     string
+		pascal    string
+		snake     string
+		kebab     string
+		screaming string
+	}{
+		{input: "", camel: "", pascal: "", snake: "", kebab: "", screaming: ""},
+		{input: "user", camel: "user", pascal: "User", snake: "user", kebab: "user", screaming: "USER"},
+		{input: "user name", camel: "userName", pascal: "UserName", snake: "user_name", kebab: "user-name", screaming: "USER_NAME"},
+		{input: "userName", camel: "userName", pascal: "UserName", snake: "user_name", kebab: "user-name", screaming: "USER_NAME"},
+		{input: "UserName", camel: "userName", pascal: "UserName", snake: "user_name", kebab: "user-name", screaming: "USER_NAME"},
+		{input: "user_name", camel: "userName", pascal: "UserName", snake: "user_name", kebab: "user-name", screaming: "USER_NAME"},
+		{input: "user-name", camel: "userName", pascal: "UserName", snake: "user_name", kebab: "user-name", screaming: "USER_NAME"},
+		{input: "USER_NAME", camel: "userName", pascal: "UserName", snake: "user_name", kebab: "user-name", screaming: "USER_NAME"},
+		{input: "HTTPServer", camel: "httpServer", pascal: "HttpServer", snake: "http_server", kebab: "http-server", screaming: "HTTP_SERVER"},
+		{input: "parseHTTP", camel: "parseHttp", pascal: "ParseHttp", snake: "parse_http", kebab: "parse-http", screaming: "PARSE_HTTP"},
+		{input: "v2Api", camel: "v2Api", pascal: "V2Api", snake: "v2_api", kebab: "v2-api", screaming: "V2_API"},
+		{input: "  --leading and trailing--  ", camel: "leadingAndTrailing", pascal: "LeadingAndTrailing", snake: "leading_and_trailing", kebab: "leading-and-trailing", screaming: "LEADING_AND_TRAILING"},
+		{input: "ÜberCafé", camel: "überCafé", pascal: "ÜberCafé", snake: "über_café", kebab: "über-café", screaming: "ÜBER_CAFÉ"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.input, func(t *testing.T) {
+			assert.Equal(t, tc.camel, camelCase(tc.input), "camel")
+			assert.Equal(t, tc.pascal, pascalCase(tc.input), "pascal")
+			assert.Equal(t, tc.snake, snakeCase(tc.input), "snake")
+			assert.Equal(t, tc.kebab, kebabCase(tc.input), "kebab")
+			assert.Equal(t, tc.screaming, screamingCase(tc.input), "screaming")
+		})
+	}
+}
+
+func TestFuncs_GoIdent(t *testing.T) {
+	tt := map[string]string{
+		"":        "_",
+		"name":    "name",
+		"my-pkg":  "my_pkg",
+		"my.pkg":  "my_pkg",
+		"my pkg!": "my_pkg_",
+		"2fa":     "_2fa",
+		"type":    "type_",
+		"func":    "func_",
+		"größe":   "größe",
+		"_ok":     "_ok",
+	}
+
+	for input, exp := range tt {
+		assert.Equal(t, exp, goIdent(input), input)
+	}
+}
+
+func TestFuncs_Pluralize(t *testing.T) {
+	tt := map[string]string{
+		"":         "",
+		"user":     "users",
+		"User":     "Users",
+		"address":  "addresses",
+		"box":      "boxes",
+		"buzz":     "buzzes",
+		"match":    "matches",
+		"dish":     "dishes",
+		"category": "categories",
+		"Category": "Categories",
+		"key":      "keys",
+		"y":        "ys",
+		"person":   "people",
+		"Person":   "People",
+		"child":    "children",
+		"sheep":    "sheep",
+		"Metadata": "Metadata",
+	}
+
+	for input, exp := range tt {
+		assert.Equal(t, exp, pluralize(input), input)
+	}
+}
+
+func TestFuncs_Indent(t *testing.T) {
+	assert.Equal(t, "  a\n\n    b\n", indent(2, "a\n\n  b\n"))
+	assert.Equal(t, "", indent(2, ""))
+	assert.Equal(t, "\n    a\n    b", nindent(4, "a\nb"))
+}
+
+func TestFuncs_Quote(t *testing.T) {
+	assert.Equal(t, `"a \"b\"\n"`, quote("a \"b\"\n"))
+	assert.Equal(t, `"42"`, quote(42))
+}
+
+func TestFuncs_JoinSplit(t *testing.T) {
+	joined, err := join(", ", []string{"a", "b"})
+	assert.NoError(t, err)
+	assert.Equal(t, "a, b", joined)
+
+	joined, err = join("-", []any{1, "b", true})
+	assert.NoError(t, err)
+	assert.Equal(t, "1-b-true", joined)
+
+	joined, err = join("-", [2]int{1, 2})
+	assert.NoError(t, err)
+	assert.Equal(t, "1-2", joined)
+
+	_, err = join(",", "ab")
+	assert.EqualError(t, err, "join: can't join string")
+
+	assert.Equal(t, []string{"a", "b", ""}, split(",", "a,b,"))
+}
+
+func TestFuncs_Default(t *testing.T) {
+	tt := []struct {
+		value any
+		exp   any
+	}{
+		{value: nil, exp: "def"},
+		{value: "", exp: "def"},
+		{value: 0, exp: "def"},
+		{value: false, exp: "def"},
+		{value: []string{}, exp: "def"},
+		{value: map[string]int{}, exp: "def"},
+		{value: (*int)(nil), exp: "def"},
+		{value: "set", exp: "set"},
+		{value: 8080, exp: 8080},
+		{value: true, exp: true},
+		{value: []string{"a"}, exp: []string{"a"}},
+	}
+
+	for _, tc := range tt {
+		assert.Equal(t, tc.exp, defaultValue("def", tc.value), fmt.Sprintf("%#v", tc.value))
+	}
+}
+
+func TestTemplateFile_Funcs(t *testing.T) {
+	data := map[string]any{
+		"Name":   "user-account",
+		"Fields": []string{"id", "email"},
+		"Port":   0,
+	}
+
+	tmpl := `// Copyright {{ year }}
+package {{ goIdent .Name }}
+
+type {{ pascal .Name }} struct {{ "{" }}{{ range .Fields }}
+	{{ pascal . }} string ` + "`json:{{ quote (camel .) }}`" + `{{ end }}
+}
+
+const table = {{ .Name | snake | pluralize | quote }}
+const columns = {{ .Fields | join ", " | quote }}
+const port = {{ .Port | default 8080 }}
+`
+
+	var b bytes.Buffer
+
+	_, err := TemplateFile("user.go", tmpl, data).(io.WriterTo).WriteTo(&b)
+	assert.NoError(t, err)
+	assert.Equal(t, `// Copyright `+strconv.Itoa(time.Now().Year())+`
+package user_account
+
+type UserAccount struct {
+	Id string `+"`json:\"id\"`"+`
+	Email string `+"`json:\"email\"`"+`
+}
+
+const table = "user_accounts"
+const columns = "id, email"
+const port = 8080
+`, b.String())
+
+	f := TemplateNamedFile("{{ snake .Name }}.go", "", data)
+	assert.Equal(t, "user_account.go", f.Name())
+}
@@ -0,0 +1,685 @@
+package drydock
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// GoImportsFormatter formats Go source code like `goimports`: unused imports are removed and
+// missing imports are added before the code is formatted like [GoFormatter].
+//
+// Missing imports are resolved against the standard library and the packages of the module
+// containing the file, which is found by reading the closest `go.mod` from the [WritableFS].
+// Only packages which already exist in the [WritableFS] can be found, so packages should be
+// generated before the files importing them.
+type GoImportsFormatter struct{}
+
+// ReadsRootFS implements [FSReadingFormatter].
+func (GoImportsFormatter) ReadsRootFS() bool {
+	return true
+}
+
+// Format implements [Formatter].
+func (GoImportsFormatter) Format(rootFS WritableFS, filename string, src []byte) ([]byte, error) {
+	fixed, err := fixImports(rootFS, filename, src)
+	if err != nil {
+		return nil, err
+	}
+
+	return GoFormatter{}.Format(rootFS, filename, fixed)
+}
+
+// textEdit replaces src[start:end] with text.
+type textEdit struct {
+	start int
+	end   int
+	text  string
+}
+
+func applyEdits(src []byte, edits []textEdit) []byte {
+	slices.SortFunc(edits, func(a, b textEdit) int {
+		if a.start != b.start {
+			return b.start - a.start
+		}
+
+		return b.end - a.end
+	})
+
+	result := slices.Clone(src)
+	for _, e := range edits {
+		result = slices.Replace(result, e.start, e.end, []byte(e.text)...)
+	}
+
+	return result
+}
+
+func fixImports(fsys fs.FS, filename string, src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, goFormatError(filename, err)
+	}
+
+	offset := func(pos token.Pos) int {
+		return fset.Position(pos).Offset
+	}
+
+	refs := packageRefs(file)
+	imported := map[string]struct{}{}
+	edits := []textEdit{}
+
+	var target importTarget
+
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+
+		removed, stdlib, other := removeUnusedImports(src, offset, gen, refs, imported)
+
+		if len(removed) == len(gen.Specs) {
+			start := gen.Pos()
+			if gen.Doc != nil {
+				start = gen.Doc.Pos()
+			}
+
+			edits = append(edits, lineEdit(src, offset(start), offset(gen.End())))
+
+			continue
+		}
+
+		edits = append(edits, removed...)
+
+		switch {
+		case target.grouped == nil && gen.Lparen.IsValid():
+			target.grouped = gen
+			target.hasStdlib, target.hasOther = stdlib, other
+		case target.single == nil && !gen.Lparen.IsValid():
+			target.single = gen
+		}
+	}
+
+	stdlibImports, otherImports, err := resolveImports(fsys, filename, file, refs, imported)
+	if err != nil {
+		return nil, err
+	}
+
+	edits = append(edits, addImports(src, offset, file, target, stdlibImports, otherImports)...)
+
+	return applyEdits(src, edits), nil
+}
+
+// removeUnusedImports returns the edits removing the unused imports of gen and records the used
+// ones in imported. It also reports whether stdlib or other imports are kept.
+func removeUnusedImports(
+	src []byte,
+	offset func(token.Pos) int,
+	gen *ast.GenDecl,
+	refs map[string]map[string]struct{},
+	imported map[string]struct{},
+) ([]textEdit, bool, bool) {
+	removed := []textEdit{}
+	stdlib, other := false, false
+
+	for _, spec := range gen.Specs {
+		imp, _ := spec.(*ast.ImportSpec)
+		name, p := importName(imp)
+
+		if _, used := refs[name]; used || name == "_" || name == "." || p == "C" {
+			imported[name] = struct{}{}
+			stdlib = stdlib || isStdlib(p)
+			other = other || !isStdlib(p)
+
+			continue
+		}
+
+		start, end := imp.Pos(), imp.End()
+		if imp.Doc != nil {
+			start = imp.Doc.Pos()
+		}
+
+		if imp.Comment != nil {
+			end = imp.Comment.End()
+		}
+
+		removed = append(removed, lineEdit(src, offset(start), offset(end)))
+	}
+
+	return removed, stdlib, other
+}
+
+// importTarget is the existing import declaration missing imports are added to.
+type importTarget struct {
+	// grouped is the first import declaration with parentheses.
+	grouped *ast.GenDecl
+	// single is the first import declaration without parentheses, which is used if there is
+	// no grouped declaration.
+	single *ast.GenDecl
+	// hasStdlib and hasOther report whether grouped keeps stdlib and other imports.
+	hasStdlib bool
+	hasOther  bool
+}
+
+// addImports returns the edits adding the missing stdlib and other imports to target. If there
+// is no import declaration, a new one is added after the package clause.
+func addImports(
+	src []byte,
+	offset func(token.Pos) int,
+	file *ast.File,
+	target importTarget,
+	stdlibImports []string,
+	otherImports []string,
+) []textEdit {
+	if len(stdlibImports) == 0 && len(otherImports) == 0 {
+		return nil
+	}
+
+	if target.grouped == nil && target.single != nil {
+		// turn the single import into a declaration with parentheses
+		imp, _ := target.single.Specs[0].(*ast.ImportSpec)
+		_, p := importName(imp)
+		existing := string(src[offset(imp.Pos()):offset(imp.End())])
+
+		specs, otherSpecs := quoteAll(stdlibImports), quoteAll(otherImports)
+		if isStdlib(p) {
+			specs = append(specs, existing)
+		} else {
+			otherSpecs = append(otherSpecs, existing)
+		}
+
+		return []textEdit{{
+			start: offset(target.single.Pos()),
+			end:   offset(target.single.End()),
+			text:  importDecl(specs, otherSpecs),
+		}}
+	}
+
+	if target.grouped == nil {
+		return []textEdit{{
+			start: offset(file.Name.End()),
+			end:   offset(file.Name.End()),
+			text:  "\n\n" + importDecl(quoteAll(stdlibImports), quoteAll(otherImports)) + "\n",
+		}}
+	}
+
+	edits := []textEdit{}
+
+	if len(stdlibImports) != 0 {
+		text := "\n" + importLines(quoteAll(stdlibImports))
+		if target.hasStdlib || !target.hasOther {
+			text = strings.TrimSuffix(text, "\n")
+		}
+
+		lparen := offset(target.grouped.Lparen) + 1
+		edits = append(edits, textEdit{start: lparen, end: lparen, text: text})
+	}
+
+	if len(otherImports) != 0 {
+		text := importLines(quoteAll(otherImports))
+		if !target.hasOther {
+			text = "\n" + text
+		}
+
+		rparen := offset(target.grouped.Rparen)
+		if start := lineStart(src, rparen); isBlank(src[start:rparen]) {
+			edits = append(edits, textEdit{start: start, end: start, text: text})
+		} else {
+			edits = append(edits, textEdit{start: rparen, end: rparen, text: "\n" + text})
+		}
+	}
+
+	return edits
+}
+
+func quoteAll(paths []string) []string {
+	quoted := make([]string, 0, len(paths))
+	for _, p := range paths {
+		quoted = append(quoted, strconv.Quote(p))
+	}
+
+	return quoted
+}
+
+func importLines(specs []string) string {
+	var b strings.Builder
+	for _, spec := range specs {
+		b.WriteString("\t" + spec + "\n")
+	}
+
+	return b.String()
+}
+
+// importDecl returns an import declaration of stdlib and other specs in separate groups.
+func importDecl(stdlibSpecs []string, otherSpecs []string) string {
+	if len(stdlibSpecs)+len(otherSpecs) == 1 {
+		return "import " + slices.Concat(stdlibSpecs, otherSpecs)[0]
+	}
+
+	if len(stdlibSpecs) != 0 && len(otherSpecs) != 0 {
+		return "import (\n" + importLines(stdlibSpecs) + "\n" + importLines(otherSpecs) + ")"
+	}
+
+	return "import (\n" + importLines(stdlibSpecs) + importLines(otherSpecs) + ")"
+}
+
+// lineEdit removes src[start:end] including the rest of its lines, if they are otherwise blank.
+func lineEdit(src []byte, start int, end int) textEdit {
+	if ls := lineStart(src, start); isBlank(src[ls:start]) {
+		start = ls
+	}
+
+	if le := lineEnd(src, end); isBlank(src[end:le]) {
+		end = le
+	}
+
+	return textEdit{start: start, end: end}
+}
+
+func lineStart(src []byte, offset int) int {
+	return bytes.LastIndexByte(src[:offset], '\n') + 1
+}
+
+func lineEnd(src []byte, offset int) int {
+	i := bytes.IndexByte(src[offset:], '\n')
+	if i < 0 {
+		return len(src)
+	}
+
+	return offset + i + 1
+}
+
+func isBlank(b []byte) bool {
+	return len(bytes.TrimSpace(b)) == 0
+}
+
+// packageRefs returns the selectors of all unresolved identifiers, which are usually package
+// names, e.g. `Println` for `fmt`.
+func packageRefs(file *ast.File) map[string]map[string]struct{} {
+	unresolved := make(map[*ast.Ident]struct{}, len(file.Unresolved))
+	for _, ident := range file.Unresolved {
+		unresolved[ident] = struct{}{}
+	}
+
+	refs := map[string]map[string]struct{}{}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+
+		x, ok := sel.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+
+		if _, ok := unresolved[x]; !ok {
+			return true
+		}
+
+		if refs[x.Name] == nil {
+			refs[x.Name] = map[string]struct{}{}
+		}
+
+		refs[x.Name][sel.Sel.Name] = struct{}{}
+
+		return true
+	})
+
+	return refs
+}
+
+// importName returns the name and path of imp. If imp has no explicit name, the name is assumed
+// from the import path.
+func importName(imp *ast.ImportSpec) (string, string) {
+	p, err := strconv.Unquote(imp.Path.Value)
+	if err != nil {
+		p = imp.Path.Value
+	}
+
+	if imp.Name != nil {
+		return imp.Name.Name, p
+	}
+
+	return assumedPackageName(p), p
+}
+
+// assumedPackageName guesses the name of a package from its import path like `goimports`.
+func assumedPackageName(importPath string) string {
+	elems := strings.Split(importPath, "/")
+
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && isMajorVersion(name) {
+		name = elems[len(elems)-2]
+	}
+
+	name = strings.TrimPrefix(name, "go-")
+
+	if i := strings.IndexFunc(name, func(r rune) bool {
+		return !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}); i >= 0 {
+		name = name[:i]
+	}
+
+	return name
+}
+
+func isMajorVersion(elem string) bool {
+	if len(elem) < 2 || elem[0] != 'v' {
+		return false
+	}
+
+	_, err := strconv.Atoi(elem[1:])
+
+	return err == nil
+}
+
+func isStdlib(importPath string) bool {
+	first, _, _ := strings.Cut(importPath, "/")
+	return !strings.Contains(first, ".")
+}
+
+// resolveImports finds the import paths of all referenced packages which are neither imported
+// nor declared by another file of the same package.
+func resolveImports(
+	fsys fs.FS,
+	filename string,
+	file *ast.File,
+	refs map[string]map[string]struct{},
+	imported map[string]struct{},
+) ([]string, []string, error) {
+	missing := []string{}
+
+	for name := range refs {
+		if _, ok := imported[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil, nil, nil
+	}
+
+	slices.Sort(missing)
+
+	dir := path.Dir(filename)
+
+	siblings, err := parseGoDir(fsys, dir, filename, file.Name.Name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	declared := declaredNames(siblings)
+
+	var mod *goModule
+	stdlibImports, otherImports := []string{}, []string{}
+
+	for _, name := range missing {
+		if _, ok := declared[name]; ok {
+			continue
+		}
+
+		if p, ok := stdlibPackage(name, refs[name]); ok {
+			stdlibImports = append(stdlibImports, p)
+			continue
+		}
+
+		if mod == nil {
+			mod, err = findGoModule(fsys, dir)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if mod == nil {
+				mod = &goModule{}
+			}
+		}
+
+		p, err := mod.findPackage(fsys, dir, name, refs[name])
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if p != "" {
+			otherImports = append(otherImports, p)
+		}
+	}
+
+	slices.Sort(stdlibImports)
+	slices.Sort(otherImports)
+
+	return stdlibImports, otherImports, nil
+}
+
+// parseGoDir parses the non test Go files of package pkg in dir, except skip. Files with syntax
+// errors are ignored. If pkg is empty, the package of the first file is used.
+func parseGoDir(fsys fs.FS, dir string, skip string, pkg string) ([]*ast.File, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	files := []*ast.File{}
+
+	for _, e := range entries {
+		p := path.Join(dir, e.Name())
+		if e.IsDir() || p == skip || !strings.HasSuffix(p, ".go") || strings.HasSuffix(p, "_test.go") {
+			continue
+		}
+
+		src, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return nil, err
+		}
+
+		f, err := parser.ParseFile(fset, p, src, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+
+		if pkg == "" {
+			pkg = f.Name.Name
+		}
+
+		if f.Name.Name == pkg {
+			files = append(files, f)
+		}
+	}
+
+	return files, nil
+}
+
+// declaredNames returns the names of all top level declarations in files.
+func declaredNames(files []*ast.File) map[string]struct{} {
+	names := map[string]struct{}{}
+
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Recv == nil {
+					names[decl.Name.Name] = struct{}{}
+				}
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.ValueSpec:
+						for _, n := range spec.Names {
+							names[n.Name] = struct{}{}
+						}
+					case *ast.TypeSpec:
+						names[spec.Name.Name] = struct{}{}
+					}
+				}
+			}
+		}
+	}
+
+	return names
+}
+
+// goModule is a module in a [WritableFS].
+type goModule struct {
+	// path is the module path. It's empty if there is no module.
+	path string
+	// dir is the directory containing `go.mod`.
+	dir string
+}
+
+// findGoModule finds the closest `go.mod` in dir or its parents. If there is none nil is returned.
+func findGoModule(fsys fs.FS, dir string) (*goModule, error) {
+	for {
+		gomod := path.Join(dir, "go.mod")
+
+		data, err := fs.ReadFile(fsys, gomod)
+		if err == nil {
+			modPath := modulePath(data)
+			if modPath == "" {
+				return nil, fmt.Errorf("%s: missing module directive", gomod)
+			}
+
+			return &goModule{path: modPath, dir: dir}, nil
+		}
+
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+
+		if dir == "." || dir == "/" {
+			return nil, nil
+		}
+
+		dir = path.Dir(dir)
+	}
+}
+
+func modulePath(gomod []byte) string {
+	for _, line := range strings.Split(string(gomod), "\n") {
+		line, _, _ = strings.Cut(line, "//")
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 || fields[0] != "module" {
+			continue
+		}
+
+		if p, err := strconv.Unquote(fields[1]); err == nil {
+			return p
+		}
+
+		return fields[1]
+	}
+
+	return ""
+}
+
+// importPath returns the import path of the package in dir.
+func (m *goModule) importPath(dir string) string {
+	if dir == m.dir {
+		return m.path
+	}
+
+	if m.dir == "." {
+		return m.path + "/" + dir
+	}
+
+	return m.path + "/" + strings.TrimPrefix(dir, m.dir+"/")
+}
+
+// findPackage returns the import path of the first package called name in the module, which
+// declares all selectors and can be imported from dir.
+func (m *goModule) findPackage(fsys fs.FS, dir string, name string, selectors map[string]struct{}) (string, error) {
+	if m.path == "" {
+		return "", nil
+	}
+
+	importer := m.importPath(dir)
+	found := ""
+
+	err := fs.WalkDir(fsys, m.dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.IsDir() {
+			return nil
+		}
+
+		if p != m.dir {
+			base := path.Base(p)
+			if strings.HasPrefix(base, ".") || strings.HasPrefix(base, "_") || base == "testdata" || base == "vendor" {
+				return fs.SkipDir
+			}
+
+			// nested module
+			if _, err := fs.Stat(fsys, path.Join(p, "go.mod")); err == nil {
+				return fs.SkipDir
+			}
+		}
+
+		if p == dir || !canImport(importer, m.importPath(p)) {
+			return nil
+		}
+
+		files, err := parseGoDir(fsys, p, "", "")
+		if err != nil {
+			return err
+		}
+
+		if len(files) == 0 || files[0].Name.Name != name {
+			return nil
+		}
+
+		declared := declaredNames(files)
+		for sel := range selectors {
+			if _, ok := declared[sel]; !ok {
+				return nil
+			}
+		}
+
+		found = m.importPath(p)
+
+		return fs.SkipAll
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return found, nil
+}
+
+// canImport reports whether importer can import imported according to the rules of
+// internal packages.
+func canImport(importer string, imported string) bool {
+	elems := strings.Split(imported, "/")
+
+	i := -1
+	for j, elem := range elems {
+		if elem == "internal" {
+			i = j
+		}
+	}
+
+	if i < 0 {
+		return true
+	}
+
+	parent := strings.Join(elems[:i], "/")
+
+	return importer == parent || strings.HasPrefix(importer, parent+"/")
+}
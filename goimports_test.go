@@ -0,0 +1,237 @@
+package drydock
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoImportsFormatter(t *testing.T) {
+	module := WritableMapFS{
+		"go.mod":                  &fstest.MapFile{Data: []byte("module example.com/app // app\n\ngo 1.22\n")},
+		"config/config.go":        &fstest.MapFile{Data: []byte("package config\n\ntype Config struct{}\n\nfunc Load() Config { return Config{} }\n")},
+		"internal/db/db.go":       &fstest.MapFile{Data: []byte("package db\n\nfunc Open() {}\n")},
+		"tools/internal/x/x.go":   &fstest.MapFile{Data: []byte("package x\n\nfunc X() {}\n")},
+		"other/config/config.go":  &fstest.MapFile{Data: []byte("package config\n\nfunc Parse() {}\n")},
+		"nested/go.mod":           &fstest.MapFile{Data: []byte("module example.com/nested\n")},
+		"nested/helpers/x.go":     &fstest.MapFile{Data: []byte("package helpers\n\nfunc Help() {}\n")},
+		"cmd/app/helpers.go":      &fstest.MapFile{Data: []byte("package main\n\nvar db = struct{ Open func() }{}\n")},
+		"cmd/app/helpers_test.go": &fstest.MapFile{Data: []byte("package main\n\nvar strings = 1\n")},
+	}
+
+	tt := []struct {
+		name     string
+		filename string
+		src      string
+		exp      string
+	}{
+		{
+			name:     "Unchanged",
+			filename: "main.go",
+			src:      "package main\n\nimport \"fmt\"\n\nfunc main() { fmt.Println() }\n",
+			exp:      "package main\n\nimport \"fmt\"\n\nfunc main() { fmt.Println() }\n",
+		},
+		{
+			name:     "Remove Unused",
+			filename: "main.go",
+			src: `package main
+
+import (
+	"fmt"
+	// for sorting
+	"sort"
+	"strings" // for joining
+
+	"example.com/app/config"
+	_ "embed"
+)
+
+func main() { fmt.Println() }
+`,
+			exp: `package main
+
+import (
+	"fmt"
+
+	_ "embed"
+)
+
+func main() { fmt.Println() }
+`,
+		},
+		{
+			name:     "Remove Whole Declaration",
+			filename: "main.go",
+			src:      "package main\n\nimport \"fmt\"\nimport \"os\"\n\nfunc main() { os.Exit(0) }\n",
+			exp:      "package main\n\nimport \"os\"\n\nfunc main() { os.Exit(0) }\n",
+		},
+		{
+			name:     "Add Missing Stdlib",
+			filename: "main.go",
+			src:      "package main\n\nfunc main() {\n\tfmt.Println(strings.Join(nil, \"\"), rand.Int())\n}\n",
+			exp:      "package main\n\nimport (\n\t\"fmt\"\n\t\"math/rand\"\n\t\"strings\"\n)\n\nfunc main() {\n\tfmt.Println(strings.Join(nil, \"\"), rand.Int())\n}\n",
+		},
+		{
+			name:     "Ambiguous Stdlib Names",
+			filename: "main.go",
+			src:      "package main\n\nfunc main() {\n\t_ = template.HTML(rand.Text())\n\t_, _ = scanner.ErrorList{}, pprof.Handler\n}\n",
+			exp:      "package main\n\nimport (\n\t\"crypto/rand\"\n\t\"go/scanner\"\n\t\"html/template\"\n\t\"net/http/pprof\"\n)\n\nfunc main() {\n\t_ = template.HTML(rand.Text())\n\t_, _ = scanner.ErrorList{}, pprof.Handler\n}\n",
+		},
+		{
+			name:     "Ambiguous Stdlib Names Without Match",
+			filename: "main.go",
+			src:      "package main\n\nfunc main() { rand.Intn(1); rand.Text() }\n",
+			exp:      "package main\n\nfunc main() { rand.Intn(1); rand.Text() }\n",
+		},
+		{
+			name:     "Add To Existing Declaration",
+			filename: "main.go",
+			src: `package main
+
+import (
+	"os"
+
+	"example.com/app/config"
+)
+
+func main() { fmt.Println(os.Args, config.Load(), filepath.Join()) }
+`,
+			exp: `package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"example.com/app/config"
+)
+
+func main() { fmt.Println(os.Args, config.Load(), filepath.Join()) }
+`,
+		},
+		{
+			name:     "Add Module Package",
+			filename: "cmd/app/main.go",
+			src:      "package main\n\nimport \"os\"\n\nfunc main() { config.Load(); os.Exit(0) }\n",
+			exp:      "package main\n\nimport (\n\t\"os\"\n\n\t\"example.com/app/config\"\n)\n\nfunc main() { config.Load(); os.Exit(0) }\n",
+		},
+		{
+			name:     "Module Package Must Declare Selectors",
+			filename: "main.go",
+			src:      "package main\n\nfunc main() { config.Parse() }\n",
+			exp:      "package main\n\nimport \"example.com/app/other/config\"\n\nfunc main() { config.Parse() }\n",
+		},
+		{
+			name:     "Declared In Other File",
+			filename: "cmd/app/main.go",
+			src:      "package main\n\nfunc main() { db.Open(); strings.ToLower(\"\") }\n",
+			exp:      "package main\n\nimport \"strings\"\n\nfunc main() { db.Open(); strings.ToLower(\"\") }\n",
+		},
+		{
+			name:     "Internal Packages",
+			filename: "main.go",
+			src:      "package main\n\nfunc main() { db.Open(); x.X() }\n",
+			exp:      "package main\n\nimport \"example.com/app/internal/db\"\n\nfunc main() { db.Open(); x.X() }\n",
+		},
+		{
+			name:     "Nested Module",
+			filename: "nested/main.go",
+			src:      "package main\n\nfunc main() { helpers.Help(); config.Load() }\n",
+			exp:      "package main\n\nimport \"example.com/nested/helpers\"\n\nfunc main() { helpers.Help(); config.Load() }\n",
+		},
+		{
+			name:     "Local Identifiers",
+			filename: "main.go",
+			src:      "package main\n\ntype T struct{ fmt int }\n\nfunc main() {\n\tvar strings T\n\t_ = strings.fmt\n}\n",
+			exp:      "package main\n\ntype T struct{ fmt int }\n\nfunc main() {\n\tvar strings T\n\t_ = strings.fmt\n}\n",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			formatted, err := GoImportsFormatter{}.Format(module, tc.filename, []byte(tc.src))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.exp, string(formatted))
+		})
+	}
+}
+
+func TestGoImportsFormatter_SyntaxError(t *testing.T) {
+	_, err := GoImportsFormatter{}.Format(WritableMapFS{}, "main.go", []byte("package main\n\nfunc main() {\n"))
+
+	var formatErr *FormatError
+	if assert.ErrorAs(t, err, &formatErr) {
+		assert.Equal(t, "main.go", formatErr.Path)
+		assert.Equal(t, 3, formatErr.Line)
+	}
+}
+
+func TestFSGenerator_Generate_GoImportsFormatter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmpdir := WritableMapFS{}
+
+	g := &FSGenerator{FS: tmpdir, Formatters: map[string]Formatter{".go": GoImportsFormatter{}}}
+
+	err := g.Generate(ctx,
+		PlainFile("go.mod", "module example.com/app\n"),
+		Dir("greeting", PlainFile("greeting.go", "package greeting\n\nfunc Hello() string { return \"hello\" }\n")),
+		TemplateFile("main.go", `package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+{{- if .Exit }}
+	os.Exit(1)
+{{- else }}
+	fmt.Println(greeting.Hello())
+{{- end }}
+}
+`, map[string]any{"Exit": false}),
+	)
+	assert.NoError(t, err)
+
+	assert.Equal(t, `package main
+
+import (
+	"fmt"
+
+	"example.com/app/greeting"
+)
+
+func main() {
+	fmt.Println(greeting.Hello())
+}
+`, string(tmpdir["main.go"].Data))
+}
+
+func TestFSGenerator_Generate_GoImportsFormatter_Concurrency(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmpdir := WritableMapFS{}
+
+	g := &FSGenerator{FS: tmpdir, Concurrency: 4, Formatters: map[string]Formatter{".go": GoImportsFormatter{}}}
+
+	err := g.Generate(ctx,
+		PlainFile("go.mod", "module example.com/m\n"),
+		Dir("util", PlainFile("util.go", "package util\n\nfunc Hello() {}\n")),
+		PlainFile("main.go", "package main\n\nfunc main() {\n\tutil.Hello()\n}\n"),
+	)
+	assert.NoError(t, err)
+
+	assert.Equal(t, `package main
+
+import "example.com/m/util"
+
+func main() {
+	util.Hello()
+}
+`, string(tmpdir["main.go"].Data))
+}
@@ -0,0 +1,59 @@
+package drydock
+
+// Group is a transparent container of files. Its files are spliced into the parent directory,
+// so they can be built declaratively, e.g. with [If] or [ForEach]. A Group has no name and
+// nothing is generated for the Group itself. Use [Flatten] when walking a file tree.
+func Group(files ...File) File {
+	return &group{files: files}
+}
+
+// If returns a [Group] of files if cond is true and an empty [Group] otherwise.
+func If(cond bool, files ...File) File {
+	if !cond {
+		return Group()
+	}
+
+	return Group(files...)
+}
+
+// Unless returns a [Group] of files if cond is false and an empty [Group] otherwise.
+func Unless(cond bool, files ...File) File {
+	return If(!cond, files...)
+}
+
+// ForEach returns a [Group] of the files returned by fn for every item. fn may return nil to
+// skip an item.
+func ForEach[T any](items []T, fn func(item T) File) File {
+	files := make([]File, 0, len(items))
+	for _, item := range items {
+		files = append(files, fn(item))
+	}
+
+	return Group(files...)
+}
+
+type group struct {
+	files []File
+}
+
+func (g *group) Name() string {
+	return ""
+}
+
+// Flatten splices the files of every [Group] in files, recursively, and removes nil files.
+// Files in directories are not flattened, they have to be flattened when walking the directory.
+func Flatten(files ...File) []File {
+	flattened := make([]File, 0, len(files))
+
+	for _, f := range files {
+		switch f := f.(type) {
+		case nil:
+		case *group:
+			flattened = append(flattened, Flatten(f.files...)...)
+		default:
+			flattened = append(flattened, f)
+		}
+	}
+
+	return flattened
+}
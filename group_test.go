@@ -0,0 +1,84 @@
+package drydock
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlatten(t *testing.T) {
+	files := Flatten(
+		PlainFile("a", ""),
+		Group(PlainFile("b", ""), Group(PlainFile("c", ""), nil), Dir("d", Group(PlainFile("e", "")))),
+		If(true, PlainFile("f", "")),
+		If(false, PlainFile("g", "")),
+		Unless(true, PlainFile("h", "")),
+		Unless(false, PlainFile("i", "")),
+		nil,
+		ForEach([]string{"j", "skip", "k"}, func(name string) File {
+			if name == "skip" {
+				return nil
+			}
+
+			return PlainFile(name, "")
+		}),
+	)
+
+	names := []string{}
+	for _, f := range files {
+		names = append(names, f.Name())
+	}
+
+	assert.Equal(t, []string{"a", "b", "c", "d", "f", "i", "j", "k"}, names)
+}
+
+func TestFSGenerator_Generate_Group(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmpdir := WritableMapFS{}
+
+	services := []string{"api", "worker"}
+	withDocker := false
+
+	files := []File{
+		PlainFile("README.md", ""),
+		Dir("cmd", ForEach(services, func(service string) File {
+			return Dir(service, PlainFile("main.go", "package main // "+service))
+		})),
+		If(withDocker, PlainFile("Dockerfile", "")),
+		Unless(withDocker, Group(PlainFile("Procfile", ""), Dir("bin"))),
+	}
+
+	err := (&FSGenerator{FS: tmpdir}).Generate(ctx, files...)
+	assert.NoError(t, err)
+
+	for _, service := range services {
+		assert.Equal(t, "package main // "+service, string(tmpdir[fmt.Sprintf("cmd/%s/main.go", service)].Data))
+	}
+
+	assert.Contains(t, tmpdir, "Procfile")
+	assert.Contains(t, tmpdir, "bin")
+	assert.NotContains(t, tmpdir, "Dockerfile")
+	assert.NoError(t, Validate(files...))
+
+	assert.Equal(t, Render(
+		PlainFile("README.md", ""),
+		Dir("cmd", Dir("api", PlainFile("main.go", "")), Dir("worker", PlainFile("main.go", ""))),
+		PlainFile("Procfile", ""),
+		Dir("bin"),
+	), Render(files...))
+
+	assert.Equal(t, Render(Dir(".", PlainFile("a", ""))), Render(Group(PlainFile("a", ""))))
+}
+
+func TestMerge_Group(t *testing.T) {
+	merged := Merge(
+		Group(Dir("cmd", PlainFile("a", ""))),
+		Dir("cmd", Group(PlainFile("b", ""))),
+	)
+
+	assert.Equal(t, Render(Dir("cmd", PlainFile("a", ""), PlainFile("b", ""))), Render(merged...))
+}
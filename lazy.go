@@ -0,0 +1,99 @@
+package drydock
+
+import (
+	"context"
+	"io"
+	"slices"
+)
+
+// LazyDirectory is a [Directory] whose entries are computed during generation with access to
+// the [WritableFS] generated into. [FSGenerator.Generate] and [FSGenerator.Plan] call
+// EntriesContext instead of Entries. dir is the path of the directory in rootFS.
+//
+// EntriesContext is called before anything is written and must not modify rootFS. With
+// [FSGenerator.CleanDir] rootFS is empty, as its contents are removed before writing.
+type LazyDirectory interface {
+	Directory
+	EntriesContext(ctx context.Context, rootFS WritableFS, dir string) ([]File, error)
+}
+
+// EntriesFunc computes the entries of a [DirFunc]. dir is the path of the directory in rootFS,
+// which may not exist yet.
+type EntriesFunc func(ctx context.Context, rootFS WritableFS, dir string) ([]File, error)
+
+// ContentsFunc computes the contents of a [FileFunc]. filename is the path of the file in rootFS.
+type ContentsFunc func(ctx context.Context, rootFS WritableFS, filename string) ([]byte, error)
+
+// DirFunc creates a [LazyDirectory] whose entries are computed by fn during generation, e.g. to
+// add the next numbered file to a directory of existing migrations. [Render] shows the
+// directory without entries and marks it as lazy.
+func DirFunc(name string, fn EntriesFunc) Directory {
+	return &dirFunc{name: name, fn: fn}
+}
+
+type dirFunc struct {
+	name string
+	fn   EntriesFunc
+}
+
+func (d *dirFunc) Name() string {
+	return d.name
+}
+
+// Entries implements [Directory]. The entries are only known during generation, so there are none.
+func (d *dirFunc) Entries() ([]File, error) {
+	return nil, nil
+}
+
+// EntriesContext implements [LazyDirectory].
+func (d *dirFunc) EntriesContext(ctx context.Context, rootFS WritableFS, dir string) ([]File, error) {
+	return d.fn(ctx, rootFS, dir)
+}
+
+// FileFunc creates a file whose contents are computed by fn when the file is written.
+func FileFunc(name string, fn ContentsFunc, opts ...FileOption) File {
+	return &fileFunc{fileOptions: newFileOptions(opts), name: name, fn: fn}
+}
+
+type fileFunc struct {
+	fileOptions
+	name string
+	fn   ContentsFunc
+}
+
+func (f *fileFunc) Name() string {
+	return f.name
+}
+
+// WriteToFileContext implements [ContextWriterToFile].
+func (f *fileFunc) WriteToFileContext(ctx context.Context, rootFS WritableFS, filename string, w io.Writer) (int64, error) {
+	contents, err := f.fn(ctx, rootFS, filename)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := w.Write(contents)
+
+	return int64(n), err
+}
+
+// dirEntries returns the entries of dir. Entries of a [LazyDirectory] are computed with rootFS,
+// unless rootFS is nil.
+func dirEntries(ctx context.Context, rootFS WritableFS, p string, dir Directory) ([]File, error) {
+	if lazy, ok := dir.(LazyDirectory); ok && rootFS != nil {
+		return lazy.EntriesContext(ctx, rootFS, p)
+	}
+
+	return dir.Entries()
+}
+
+// isLazy reports whether the entries of d are only known during generation.
+func isLazy(d Directory) bool {
+	if m, ok := d.(*mergedDir); ok {
+		return slices.ContainsFunc(m.dirs, isLazy)
+	}
+
+	_, ok := d.(LazyDirectory)
+
+	return ok
+}
@@ -0,0 +1,136 @@
+package drydock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func nextMigration(name string) EntriesFunc {
+	return func(_ context.Context, rootFS WritableFS, dir string) ([]File, error) {
+		entries, err := fs.ReadDir(rootFS, dir)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+
+		return []File{PlainFile(fmt.Sprintf("%03d_%s.sql", len(entries)+1, name), "-- "+name)}, nil
+	}
+}
+
+func TestFSGenerator_Generate_DirFunc(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmpdir := WritableMapFS{}
+
+	g := &FSGenerator{FS: tmpdir}
+
+	err := g.Generate(ctx, Dir("db", DirFunc("migrations", nextMigration("init"))))
+	assert.NoError(t, err)
+
+	err = g.Generate(ctx, Dir("db", DirFunc("migrations", nextMigration("users"))))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "-- init", string(tmpdir["db/migrations/001_init.sql"].Data))
+	assert.Equal(t, "-- users", string(tmpdir["db/migrations/002_users.sql"].Data))
+
+	plan, err := g.Plan(ctx, Dir("db", DirFunc("migrations", nextMigration("posts"))))
+	assert.NoError(t, err)
+	assert.Equal(t, []Operation{{Op: OpCreate, Path: "db/migrations/003_posts.sql"}}, plan.Operations)
+
+	errFailing := errors.New("failing")
+
+	err = g.Generate(ctx, DirFunc("fail", func(context.Context, WritableFS, string) ([]File, error) {
+		return nil, errFailing
+	}))
+	assert.ErrorIs(t, err, errFailing)
+}
+
+func TestFSGenerator_Generate_DirFunc_Merged(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmpdir := WritableMapFS{
+		"app/db/migrations/001_init.sql": &fstest.MapFile{Data: []byte("-- init")},
+	}
+
+	merged := Merge(
+		Dir("db", PlainFile("schema.sql", "")),
+		Dir("db", DirFunc("migrations", nextMigration("users"))),
+	)
+
+	err := (&FSGenerator{FS: tmpdir}).Generate(ctx, Dir("app", merged...))
+	assert.NoError(t, err)
+	assert.Contains(t, tmpdir, "app/db/schema.sql")
+	assert.Equal(t, "-- users", string(tmpdir["app/db/migrations/002_users.sql"].Data))
+}
+
+func TestFSGenerator_Generate_FileFunc(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmpdir := WritableMapFS{
+		"VERSION": &fstest.MapFile{Data: []byte("1")},
+	}
+
+	g := &FSGenerator{FS: tmpdir, SkipUnchanged: true}
+
+	bump := FileFunc("VERSION", func(_ context.Context, rootFS WritableFS, filename string) ([]byte, error) {
+		version, err := rootFS.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+
+		return append(version, '1'), nil
+	}, WithMode(0600))
+
+	err := g.Generate(ctx, bump)
+	assert.NoError(t, err)
+	assert.Equal(t, "11", string(tmpdir["VERSION"].Data))
+	assert.Equal(t, fs.FileMode(0600), tmpdir["VERSION"].Mode)
+}
+
+func TestRender_Lazy(t *testing.T) {
+	rendered := Render(
+		PlainFile("go.mod", ""),
+		Dir("db", PlainFile("schema.sql", ""), DirFunc("migrations", nextMigration("init"))),
+	)
+
+	assert.Equal(t, `.
+├── go.mod
+└── db/
+    ├── schema.sql
+    └── migrations/ (lazy)
+`, strings.ReplaceAll(rendered, " ", " "))
+
+	assert.NoError(t, Validate(DirFunc("migrations", nextMigration("init"))))
+}
+
+func TestFSGenerator_Generate_DirFunc_CleanDir(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmpdir := WritableMapFS{
+		"db/migrations/001_init.sql":  &fstest.MapFile{Data: []byte("-- init")},
+		"db/migrations/002_users.sql": &fstest.MapFile{Data: []byte("-- users")},
+	}
+
+	g := &FSGenerator{FS: tmpdir, CleanDir: true}
+
+	plan, err := g.Plan(ctx, Dir("db", DirFunc("migrations", nextMigration("posts"))))
+	assert.NoError(t, err)
+	assert.Contains(t, plan.Operations, Operation{Op: OpCreate, Path: "db/migrations/001_posts.sql"})
+
+	err = g.Generate(ctx, Dir("db", DirFunc("migrations", nextMigration("posts"))))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "-- posts", string(tmpdir["db/migrations/001_posts.sql"].Data))
+	assert.NotContains(t, tmpdir, "db/migrations/003_posts.sql")
+	assert.NotContains(t, tmpdir, "db/migrations/001_init.sql")
+}
@@ -0,0 +1,218 @@
+package drydock
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"time"
+)
+
+// DefaultManifestPath is the conventional location of the [Manifest] inside the output FS.
+const DefaultManifestPath = ".drydock/manifest.json"
+
+// ErrInvalidManifest is returned when an existing manifest can't be read.
+var ErrInvalidManifest = errors.New("invalid manifest")
+
+// Manifest records every file generated by [FSGenerator] together with the blueprint which
+// produced it and a hash of its contents, so later runs can detect whether a file was edited.
+type Manifest struct {
+	Files map[string]ManifestFile `json:"files"`
+}
+
+// ManifestFile is a single generated file in a [Manifest].
+type ManifestFile struct {
+	Blueprint string `json:"blueprint,omitempty"`
+	SHA256    string `json:"sha256"`
+}
+
+// ReadManifest reads the manifest at name from fsys. If the manifest does not exist an empty
+// manifest is returned. Manifests with paths which are not valid according to [fs.ValidPath]
+// are invalid.
+func ReadManifest(fsys fs.FS, name string) (*Manifest, error) {
+	m := &Manifest{Files: map[string]ManifestFile{}}
+
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return m, nil
+		}
+
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, m)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidManifest, name, err)
+	}
+
+	if m.Files == nil {
+		m.Files = map[string]ManifestFile{}
+	}
+
+	// stale files are removed, so every path must stay inside of fsys
+	for p := range m.Files {
+		if !fs.ValidPath(p) || p == "." {
+			return nil, fmt.Errorf("%w: %s: invalid path %q", ErrInvalidManifest, name, p)
+		}
+	}
+
+	return m, nil
+}
+
+// Generated reports whether p was generated according to the manifest.
+func (m *Manifest) Generated(p string) bool {
+	_, ok := m.Files[p]
+	return ok
+}
+
+// Unmodified reports whether p was generated and contents are still the same as when
+// it was generated.
+func (m *Manifest) Unmodified(p string, contents []byte) bool {
+	f, ok := m.Files[p]
+	if !ok {
+		return false
+	}
+
+	return f.SHA256 == hashContents(contents)
+}
+
+func hashContents(contents []byte) string {
+	sum := sha256.Sum256(contents)
+	return hex.EncodeToString(sum[:])
+}
+
+// manifests tracks the manifest of the previous run and the manifest of the current run.
+type manifests struct {
+	prev *Manifest
+	next *Manifest
+}
+
+func (g *FSGenerator) readManifest(fsys fs.FS) (*Manifest, error) {
+	if g.manifestPath() == "" {
+		return nil, nil
+	}
+
+	return ReadManifest(fsys, g.manifestPath())
+}
+
+func (g *FSGenerator) manifestPath() string {
+	if g.ManifestPath == "" && g.Prune {
+		return DefaultManifestPath
+	}
+
+	return g.ManifestPath
+}
+
+func (g *FSGenerator) loadManifests() error {
+	g.manifests = nil
+
+	prev, err := g.readManifest(g.fsys)
+	if err != nil || prev == nil {
+		return err
+	}
+
+	next := &Manifest{Files: make(map[string]ManifestFile, len(prev.Files))}
+
+	// keep files generated by other blueprints
+	for p, f := range prev.Files {
+		if f.Blueprint != g.Blueprint {
+			next.Files[p] = f
+		}
+	}
+
+	g.manifests = &manifests{prev: prev, next: next}
+
+	return nil
+}
+
+// unmodified reports whether the existing file p was generated by a previous run and
+// has not been changed since.
+func (g *FSGenerator) unmodified(p string) (bool, error) {
+	if g.manifests == nil || !g.manifests.prev.Generated(p) {
+		return false, nil
+	}
+
+	existing, err := g.fsys.ReadFile(p)
+	if err != nil {
+		return false, err
+	}
+
+	return g.manifests.prev.Unmodified(p, existing), nil
+}
+
+// recordFile adds p to the manifest of the current run.
+func (g *FSGenerator) recordFile(p string, sha string) {
+	if g.manifests == nil {
+		return
+	}
+
+	g.manifests.next.Files[p] = ManifestFile{Blueprint: g.Blueprint, SHA256: sha}
+}
+
+// keepFile keeps the entry of the previous run for p, e.g. when p was skipped.
+func (g *FSGenerator) keepFile(p string) {
+	if g.manifests == nil {
+		return
+	}
+
+	if f, ok := g.manifests.prev.Files[p]; ok {
+		g.manifests.next.Files[p] = f
+	}
+}
+
+func (g *FSGenerator) writeManifest(ctx context.Context) error {
+	if g.manifests == nil {
+		return nil
+	}
+
+	err := g.mkdirAll(path.Dir(g.manifestPath()))
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(g.manifests.next, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	manifest := &genfile{
+		path:       g.manifestPath(),
+		contents:   &renderedContents{append(data, '\n')},
+		isNewFile:  true,
+		noManifest: true,
+	}
+
+	existed, err := fileExists(g.fsys, manifest.path)
+	if err != nil {
+		return err
+	}
+
+	return g.writeRealFile(ctx, manifest, existed, time.Now())
+}
+
+func (g *FSGenerator) mkdirAll(dir string) error {
+	if dir == "." || dir == "/" {
+		return nil
+	}
+
+	err := g.mkdirAll(path.Dir(dir))
+	if err != nil {
+		return err
+	}
+
+	if _, created := g.createdDirs[dir]; created {
+		return nil
+	}
+
+	exists, err := fileExists(g.fsys, dir)
+	if err != nil || exists {
+		return err
+	}
+
+	return g.generateRealDir(dir, 0)
+}
@@ -0,0 +1,130 @@
+package drydock
+
+import (
+	"context"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFSGenerator_Generate_Manifest(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmpdir := WritableMapFS{}
+
+	g := &FSGenerator{
+		FS:                  tmpdir,
+		ManifestPath:        DefaultManifestPath,
+		Blueprint:           "service",
+		ErrorOnExistingFile: true,
+	}
+
+	blueprint := []File{
+		PlainFile("README.md", "readme"),
+		Dir("cmd", PlainFile("main.go", "package main")),
+	}
+
+	err := g.Generate(ctx, blueprint...)
+	assert.NoError(t, err)
+
+	manifest, err := ReadManifest(tmpdir, DefaultManifestPath)
+	assert.NoError(t, err)
+	assert.Equal(t, &Manifest{Files: map[string]ManifestFile{
+		"README.md":   {Blueprint: "service", SHA256: hashContents([]byte("readme"))},
+		"cmd/main.go": {Blueprint: "service", SHA256: hashContents([]byte("package main"))},
+	}}, manifest)
+
+	// unedited files are overwritten even though ErrorOnExistingFile is set
+	err = g.Generate(ctx, blueprint...)
+	assert.NoError(t, err)
+
+	tmpdir["cmd/main.go"].Data = []byte("package main // edited")
+
+	err = g.Generate(ctx, blueprint...)
+	assert.ErrorIs(t, err, fs.ErrExist)
+
+	var conflicts []*Conflict
+
+	g.ErrorOnExistingFile = false
+	g.ConflictResolver = ConflictResolverFunc(func(_ context.Context, c *Conflict) (Resolution, error) {
+		conflicts = append(conflicts, c)
+		return ConflictSkip, nil
+	})
+
+	err = g.Generate(ctx, blueprint...)
+	assert.NoError(t, err)
+
+	assert.Len(t, conflicts, 1)
+	assert.Equal(t, "cmd/main.go", conflicts[0].Path)
+	assert.True(t, conflicts[0].Edited)
+
+	manifest, err = ReadManifest(tmpdir, DefaultManifestPath)
+	assert.NoError(t, err)
+	assert.Equal(t, hashContents([]byte("package main")), manifest.Files["cmd/main.go"].SHA256)
+}
+
+func TestFSGenerator_Generate_Manifest_Blueprints(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmpdir := WritableMapFS{}
+
+	g := &FSGenerator{FS: tmpdir, ManifestPath: DefaultManifestPath, Blueprint: "a"}
+
+	err := g.Generate(ctx, PlainFile("a.txt", "a"))
+	assert.NoError(t, err)
+
+	g.Blueprint = "b"
+
+	err = g.Generate(ctx, PlainFile("b.txt", "b"))
+	assert.NoError(t, err)
+
+	manifest, err := ReadManifest(tmpdir, DefaultManifestPath)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]ManifestFile{
+		"a.txt": {Blueprint: "a", SHA256: hashContents([]byte("a"))},
+		"b.txt": {Blueprint: "b", SHA256: hashContents([]byte("b"))},
+	}, manifest.Files)
+}
+
+func TestFSGenerator_Plan_Manifest(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmpdir := WritableMapFS{
+		"README.md": &fstest.MapFile{Data: []byte("readme")},
+		".drydock/manifest.json": &fstest.MapFile{
+			Data: []byte(`{"files": {"README.md": {"sha256": "` + hashContents([]byte("readme")) + `"}}}`),
+		},
+	}
+
+	g := &FSGenerator{FS: tmpdir, ManifestPath: DefaultManifestPath, ErrorOnExistingFile: true}
+
+	plan, err := g.Plan(ctx, PlainFile("README.md", "new readme"))
+	assert.NoError(t, err)
+	assert.Equal(t, "overwrite README.md\noverwrite .drydock/manifest.json\n", plan.String())
+}
+
+func TestReadManifest_InvalidPath(t *testing.T) {
+	for _, p := range []string{"../victim", "dir/../../victim", "/etc/passwd", "."} {
+		t.Run(p, func(t *testing.T) {
+			fsys := fstest.MapFS{
+				"manifest.json": &fstest.MapFile{Data: []byte(`{"files": {"` + p + `": {"sha256": "abc"}}}`)},
+			}
+
+			_, err := ReadManifest(fsys, "manifest.json")
+			assert.ErrorIs(t, err, ErrInvalidManifest)
+		})
+	}
+
+	fsys := fstest.MapFS{
+		"manifest.json": &fstest.MapFile{Data: []byte(`{"files": {"dir/file.txt": {"sha256": "abc"}}}`)},
+	}
+
+	m, err := ReadManifest(fsys, "manifest.json")
+	assert.NoError(t, err)
+	assert.True(t, m.Generated("dir/file.txt"))
+}
@@ -0,0 +1,195 @@
+package drydock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+)
+
+// MergePolicy decides which file [MergeWith] keeps if multiple files have the same path.
+type MergePolicy int
+
+const (
+	// MergeError fails with [ErrDuplicatePath].
+	MergeError MergePolicy = iota + 1
+	// MergeLastWins keeps the file declared last.
+	MergeLastWins
+	// MergeFirstWins keeps the file declared first.
+	MergeFirstWins
+)
+
+func (p MergePolicy) String() string {
+	switch p {
+	case MergeError:
+		return "error"
+	case MergeLastWins:
+		return "last-wins"
+	case MergeFirstWins:
+		return "first-wins"
+	default:
+		return fmt.Sprintf("MergePolicy(%d)", int(p))
+	}
+}
+
+// Merge unifies directories with the same path recursively, so files can be composed from
+// multiple trees. Conflicting files are resolved with [MergeLastWins].
+func Merge(files ...File) []File {
+	merged, _ := MergeWith(MergeLastWins, files...)
+	return merged
+}
+
+// MergeWith is like [Merge] but resolves conflicting files with policy. A file and a directory
+// with the same path conflict as well, files modifying existing files, like [ModifyFile], never
+// conflict.
+//
+// Directories are merged recursively, so conflicts inside directories are returned by MergeWith
+// as well. Directories containing a [LazyDirectory] are merged during generation, so their
+// conflicts are returned by [FSGenerator.Generate].
+func MergeWith(policy MergePolicy, files ...File) ([]File, error) {
+	return merge(policy, "", files)
+}
+
+func merge(policy MergePolicy, parentDir string, files []File) ([]File, error) {
+	merged := make([]File, 0, len(files))
+	index := map[string]int{}
+
+	for _, f := range Flatten(files...) {
+		if m, ok := f.(IsNewFile); ok && !m.IsNewFile() {
+			merged = append(merged, f)
+			continue
+		}
+
+		var dir *mergedDir
+		if d, ok := f.(Directory); ok {
+			dir = mergedDirOf(policy, parentDir, d)
+			f = dir
+		}
+
+		i, exists := index[f.Name()]
+		if !exists {
+			index[f.Name()] = len(merged)
+			merged = append(merged, f)
+
+			continue
+		}
+
+		if existing, ok := merged[i].(*mergedDir); ok && dir != nil {
+			existing.dirs = slices.Concat(existing.dirs, dir.dirs)
+			continue
+		}
+
+		switch policy {
+		case MergeLastWins:
+			merged[i] = f
+		case MergeFirstWins:
+			// keep the existing file
+		case MergeError:
+			return nil, fmt.Errorf("%w: %s", ErrDuplicatePath, joinPath(parentDir, f.Name()))
+		default:
+			return nil, fmt.Errorf("%w: %s: unknown merge policy %s", ErrDuplicatePath, joinPath(parentDir, f.Name()), policy)
+		}
+	}
+
+	for i, f := range merged {
+		dir, ok := f.(*mergedDir)
+		if !ok {
+			continue
+		}
+
+		resolved, err := dir.resolve()
+		if err != nil {
+			return nil, err
+		}
+
+		merged[i] = resolved
+	}
+
+	return merged, nil
+}
+
+func mergedDirOf(policy MergePolicy, parentDir string, dir Directory) *mergedDir {
+	if m, ok := dir.(*mergedDir); ok && m.policy == policy {
+		return &mergedDir{name: m.name, path: joinPath(parentDir, m.name), policy: policy, dirs: m.dirs}
+	}
+
+	return &mergedDir{name: dir.Name(), path: joinPath(parentDir, dir.Name()), policy: policy, dirs: []Directory{dir}}
+}
+
+// mergedDir is a directory whose entries are the merged entries of dirs.
+type mergedDir struct {
+	name   string
+	path   string
+	policy MergePolicy
+	dirs   []Directory
+}
+
+func (d *mergedDir) Name() string {
+	return d.name
+}
+
+func (d *mergedDir) nameError() error {
+	for _, dir := range d.dirs {
+		if err := nameErrorOf(dir); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resolve merges the entries of d into a static directory, unless d contains a [LazyDirectory].
+// If the entries can't be read, d is kept, so the error is returned when d is generated.
+func (d *mergedDir) resolve() (File, error) {
+	if isLazy(d) {
+		return d, nil
+	}
+
+	entries, err := d.entries(context.Background(), nil, d.path)
+	if err != nil {
+		if errors.Is(err, ErrDuplicatePath) {
+			return nil, err
+		}
+
+		return d, nil
+	}
+
+	return &dir{name: d.name, nameErr: d.nameError(), entries: entries, mode: d.Mode()}, nil
+}
+
+func (d *mergedDir) Entries() ([]File, error) {
+	return d.entries(context.Background(), nil, d.path)
+}
+
+// EntriesContext implements [LazyDirectory], so lazy directories can be merged.
+func (d *mergedDir) EntriesContext(ctx context.Context, rootFS WritableFS, dir string) ([]File, error) {
+	return d.entries(ctx, rootFS, dir)
+}
+
+// entries merges the entries of all directories. p is the path of the directory.
+func (d *mergedDir) entries(ctx context.Context, rootFS WritableFS, p string) ([]File, error) {
+	entries := []File{}
+
+	for _, dir := range d.dirs {
+		e, err := dirEntries(ctx, rootFS, p, dir)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, e...)
+	}
+
+	return merge(d.policy, p, entries)
+}
+
+// Mode implements [FileMode]. The first explicit mode of the merged directories is used.
+func (d *mergedDir) Mode() fs.FileMode {
+	for _, dir := range d.dirs {
+		if mode := modeOf(dir); mode != 0 {
+			return mode
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,131 @@
+package drydock
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeWith(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	featureA := []File{
+		Dir("cmd", Dir("server", PlainFile("main.go", "a"))),
+		PlainFile("README.md", "a"),
+		PlainFile("config.json", `{"a": true}`),
+	}
+
+	featureB := []File{
+		Dir("cmd", Dir("server", PlainFile("main.go", "b"), PlainFile("flags.go", "b")), Dir("cli")),
+		PlainFile("README.md", "b"),
+		ModifyFile("config.json", json.Unmarshal, func(c *map[string]any) ([]byte, error) {
+			(*c)["b"] = true
+			return json.Marshal(c)
+		}),
+	}
+
+	tt := []struct {
+		policy MergePolicy
+		exp    map[string]string
+	}{
+		{
+			policy: MergeLastWins,
+			exp: map[string]string{
+				"README.md":           "b",
+				"cmd/server/main.go":  "b",
+				"cmd/server/flags.go": "b",
+				"config.json":         `{"a":true,"b":true}`,
+			},
+		},
+		{
+			policy: MergeFirstWins,
+			exp: map[string]string{
+				"README.md":           "a",
+				"cmd/server/main.go":  "a",
+				"cmd/server/flags.go": "b",
+				"config.json":         `{"a":true,"b":true}`,
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.policy.String(), func(t *testing.T) {
+			merged, err := MergeWith(tc.policy, append(featureA, featureB...)...)
+			assert.NoError(t, err)
+			assert.Len(t, merged, 4)
+
+			tmpdir := WritableMapFS{}
+
+			err = (&FSGenerator{FS: tmpdir, ErrorOnExistingDir: true}).Generate(ctx, merged...)
+			assert.NoError(t, err)
+
+			for p, contents := range tc.exp {
+				assert.Equal(t, contents, string(tmpdir[p].Data), p)
+			}
+
+			assert.True(t, tmpdir["cmd/cli"].Mode.IsDir())
+		})
+	}
+}
+
+func TestMergeWith_MergeError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	_, err := MergeWith(MergeError, PlainFile("README.md", "a"), Dir("README.md"))
+	assert.ErrorIs(t, err, ErrDuplicatePath)
+	assert.EqualError(t, err, "duplicate path: README.md")
+
+	_, err = MergeWith(MergeError,
+		Dir("cmd", Dir("server", PlainFile("main.go", "a"))),
+		Dir("cmd", Dir("server", PlainFile("main.go", "b"))),
+	)
+	assert.ErrorIs(t, err, ErrDuplicatePath)
+	assert.EqualError(t, err, "duplicate path: cmd/server/main.go")
+
+	_, err = MergeWith(MergeError, Dir("cmd", PlainFile("a", "1")), Dir("cmd", PlainFile("a", "2")))
+	assert.EqualError(t, err, "duplicate path: cmd/a")
+
+	merged, err := MergeWith(MergeError,
+		Dir("db", DirFunc("migrations", func(context.Context, WritableFS, string) ([]File, error) {
+			return []File{PlainFile("001_init.sql", "a")}, nil
+		})),
+		Dir("db", Dir("migrations", PlainFile("001_init.sql", "b"))),
+	)
+	assert.NoError(t, err)
+
+	err = (&FSGenerator{FS: WritableMapFS{}}).Generate(ctx, merged...)
+	assert.ErrorIs(t, err, ErrDuplicatePath)
+	assert.ErrorContains(t, err, "duplicate path: db/migrations/001_init.sql")
+}
+
+func TestMerge(t *testing.T) {
+	merged := Merge(
+		Dir("a", Dir("b", PlainFile("c", "1")), Dir("b", PlainFile("d", ""))),
+		DirMode("a", 0700, Dir("b", PlainFile("c", "2"))),
+	)
+
+	assert.Len(t, merged, 1)
+	assert.Equal(t, fs.FileMode(0700), modeOf(merged[0]))
+
+	entries, err := merged[0].(Directory).Entries()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	entries, err = entries[0].(Directory).Entries()
+	assert.NoError(t, err)
+
+	names := []string{}
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+
+	assert.Equal(t, []string{"c", "d"}, names)
+	assert.Equal(t, "2", string(entries[0].(*plainFile).contents))
+
+	assert.Equal(t, Render(Dir("a", Dir("b", PlainFile("c", ""), PlainFile("d", "")))), Render(merged...))
+}
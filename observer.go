@@ -0,0 +1,91 @@
+package drydock
+
+// Observer is notified by [FSGenerator.Generate] about its progress, e.g. to drive progress bars
+// or structured logs. All methods are called from the goroutine calling [FSGenerator.Generate],
+// even when files are rendered concurrently.
+type Observer interface {
+	// DirCreated is called after a directory was created.
+	DirCreated(path string)
+
+	// FileWriting is called before a file is written.
+	FileWriting(path string)
+
+	// FileWritten is called after a file was written.
+	FileWritten(entry ReportEntry)
+
+	// FileSkipped is called for files which were not written.
+	FileSkipped(path string)
+
+	// Removed is called after a file or directory was removed by [FSGenerator.Prune].
+	Removed(path string)
+
+	// Error is called with the error [FSGenerator.Generate] will return. path is the file or
+	// directory which caused the error or empty if the error is not related to a single path.
+	// With [FSGenerator.ContinueOnError] Error is called for every failure.
+	Error(path string, err error)
+}
+
+// ObserverFuncs implements [Observer] using optional callbacks. Nil callbacks are ignored.
+type ObserverFuncs struct {
+	OnDirCreated  func(path string)
+	OnFileWriting func(path string)
+	OnFileWritten func(entry ReportEntry)
+	OnFileSkipped func(path string)
+	OnRemoved     func(path string)
+	OnError       func(path string, err error)
+}
+
+var _ Observer = (*ObserverFuncs)(nil)
+
+func (o *ObserverFuncs) DirCreated(path string) {
+	if o.OnDirCreated != nil {
+		o.OnDirCreated(path)
+	}
+}
+
+func (o *ObserverFuncs) FileWriting(path string) {
+	if o.OnFileWriting != nil {
+		o.OnFileWriting(path)
+	}
+}
+
+func (o *ObserverFuncs) FileWritten(entry ReportEntry) {
+	if o.OnFileWritten != nil {
+		o.OnFileWritten(entry)
+	}
+}
+
+func (o *ObserverFuncs) FileSkipped(path string) {
+	if o.OnFileSkipped != nil {
+		o.OnFileSkipped(path)
+	}
+}
+
+func (o *ObserverFuncs) Removed(path string) {
+	if o.OnRemoved != nil {
+		o.OnRemoved(path)
+	}
+}
+
+func (o *ObserverFuncs) Error(path string, err error) {
+	if o.OnError != nil {
+		o.OnError(path, err)
+	}
+}
+
+type nopObserver struct{}
+
+func (nopObserver) DirCreated(string)       {}
+func (nopObserver) FileWriting(string)      {}
+func (nopObserver) FileWritten(ReportEntry) {}
+func (nopObserver) FileSkipped(string)      {}
+func (nopObserver) Removed(string)          {}
+func (nopObserver) Error(string, error)     {}
+
+func (g *FSGenerator) observer() Observer {
+	if g.Observer == nil {
+		return nopObserver{}
+	}
+
+	return g.Observer
+}
@@ -0,0 +1,52 @@
+package drydock
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFSGenerator_Generate_Observer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	events := []string{}
+
+	g := &FSGenerator{
+		FS: WritableMapFS{},
+		Observer: &ObserverFuncs{
+			OnDirCreated: func(path string) {
+				events = append(events, "dir created "+path)
+			},
+			OnFileWriting: func(path string) {
+				events = append(events, "file writing "+path)
+			},
+			OnFileWritten: func(entry ReportEntry) {
+				events = append(events, "file written "+entry.Path+" "+entry.Action.String())
+			},
+			OnFileSkipped: func(path string) {
+				events = append(events, "file skipped "+path)
+			},
+			OnError: func(path string, err error) {
+				events = append(events, "error "+path+": "+err.Error())
+			},
+		},
+	}
+
+	err := g.Generate(ctx,
+		PlainFile("README.md", "readme"),
+		Dir("bin", nameOnlyFile("skipped")),
+		&erroringFile{name: "fail", err: errFailingFile},
+	)
+	assert.ErrorIs(t, err, errFailingFile)
+
+	assert.Equal(t, []string{
+		"dir created bin",
+		"file writing README.md",
+		"file written README.md created",
+		"file skipped bin/skipped",
+		"file writing fail",
+		"error fail: render fail: failing file",
+	}, events)
+}
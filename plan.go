@@ -0,0 +1,363 @@
+package drydock
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+)
+
+// OpType is the kind of change an [Operation] will make to the [WritableFS].
+type OpType int
+
+const (
+	// OpMkdir creates a new directory.
+	OpMkdir OpType = iota + 1
+	// OpCreate writes a file that does not exist yet.
+	OpCreate
+	// OpOverwrite replaces the contents of an existing file.
+	OpOverwrite
+	// OpModify updates an existing file, e.g. a file created by [ModifyFile].
+	OpModify
+	// OpRemove removes an existing file or directory.
+	OpRemove
+)
+
+func (o OpType) String() string {
+	switch o {
+	case OpMkdir:
+		return "mkdir"
+	case OpCreate:
+		return "create"
+	case OpOverwrite:
+		return "overwrite"
+	case OpModify:
+		return "modify"
+	case OpRemove:
+		return "remove"
+	default:
+		return fmt.Sprintf("OpType(%d)", int(o))
+	}
+}
+
+// Operation is a single change [FSGenerator.Generate] would make.
+type Operation struct {
+	Op   OpType
+	Path string
+}
+
+// Plan is the ordered list of operations [FSGenerator.Generate] would perform.
+type Plan struct {
+	Operations []Operation
+}
+
+// Count returns the number of operations of type op.
+func (p *Plan) Count(op OpType) int {
+	count := 0
+	for _, o := range p.Operations {
+		if o.Op == op {
+			count++
+		}
+	}
+
+	return count
+}
+
+// String renders the plan with one operation per line.
+func (p *Plan) String() string {
+	var b strings.Builder
+
+	for _, o := range p.Operations {
+		b.WriteString(o.Op.String())
+		b.WriteString(" ")
+		b.WriteString(o.Path)
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// Plan computes the operations [FSGenerator.Generate] would perform for files without
+// writing anything to the [WritableFS]. Errors [FSGenerator.Generate] would return because
+// of ErrorOnExistingDir or ErrorOnExistingFile are returned as well.
+func (g *FSGenerator) Plan(ctx context.Context, files ...File) (*Plan, error) {
+	if g.FS == nil {
+		return nil, ErrMissingFS
+	}
+
+	gendirs, genfiles, err := g.collect(ctx, g.lazyRootFS(g.FS), files)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validate(gendirs, genfiles)
+	if err != nil {
+		return nil, err
+	}
+
+	planner := &planner{g: g, plan: &Plan{}, planned: map[string]struct{}{}}
+
+	if g.CleanDir {
+		err = planner.planCleanDir()
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		planner.manifest, err = g.readManifest(g.FS)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, d := range gendirs {
+		err = planner.planDir(d)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, f := range genfiles {
+		err = planner.planFile(ctx, f)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = planner.planPrune(gendirs, genfiles)
+	if err != nil {
+		return nil, err
+	}
+
+	if g.manifestPath() != "" {
+		err = planner.planManifest()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return planner.plan, nil
+}
+
+type planner struct {
+	g        *FSGenerator
+	plan     *Plan
+	cleaned  bool
+	planned  map[string]struct{}
+	manifest *Manifest
+}
+
+func (p *planner) add(op OpType, path string) {
+	p.plan.Operations = append(p.plan.Operations, Operation{Op: op, Path: path})
+	p.planned[path] = struct{}{}
+}
+
+func (p *planner) planCleanDir() error {
+	entries, err := fs.ReadDir(p.g.FS, ".")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %w", ErrCleaningOutputDir, err)
+	}
+
+	for _, e := range entries {
+		p.plan.Operations = append(p.plan.Operations, Operation{Op: OpRemove, Path: e.Name()})
+	}
+
+	p.cleaned = true
+
+	return nil
+}
+
+func (p *planner) exists(path string) (bool, error) {
+	if _, planned := p.planned[path]; planned {
+		return true, nil
+	}
+
+	if p.cleaned {
+		return false, nil
+	}
+
+	return fileExists(p.g.FS, path)
+}
+
+func (p *planner) planDir(d *gendir) error {
+	if _, planned := p.planned[d.path]; planned {
+		return nil
+	}
+
+	exists, err := p.exists(d.path)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		p.add(OpMkdir, d.path)
+		return nil
+	}
+
+	if p.g.ErrorOnExistingDir {
+		return &fs.PathError{Op: "mkdir", Path: d.path, Err: fs.ErrExist}
+	}
+
+	return nil
+}
+
+func (p *planner) planFile(ctx context.Context, f *genfile) error {
+	if f.symlink != "" {
+		return p.planSymlink(f)
+	}
+
+	if f.contents == nil {
+		return nil
+	}
+
+	exists, err := p.exists(f.path)
+	if err != nil {
+		return err
+	}
+
+	if exists && p.g.SkipUnchanged {
+		unchanged, err := p.unchanged(ctx, f)
+		if err != nil || unchanged {
+			return err
+		}
+	}
+
+	switch {
+	case !exists:
+		p.add(OpCreate, f.path)
+	case !f.isNewFile:
+		p.add(OpModify, f.path)
+	case p.unmodified(f.path):
+		p.add(OpOverwrite, f.path)
+	case p.g.ErrorOnExistingFile:
+		return fmt.Errorf("file already exits %s: %w", f.path, fs.ErrExist)
+	default:
+		return p.planConflict(f)
+	}
+
+	return nil
+}
+
+// unchanged renders f and compares it with the existing file. Files planned in this run are
+// always considered changed.
+func (p *planner) unchanged(ctx context.Context, f *genfile) (bool, error) {
+	if _, planned := p.planned[f.path]; planned || p.cleaned {
+		return false, nil
+	}
+
+	f, err := p.g.format(ctx, p.g.FS, f)
+	if err != nil {
+		return false, err
+	}
+
+	if f.verbatim {
+		equal, _, err := equalContents(ctx, p.g.FS, f)
+		if err != nil || !equal {
+			return false, err
+		}
+	} else {
+		existing, err := p.g.FS.ReadFile(f.path)
+		if err != nil {
+			return false, err
+		}
+
+		var b bytes.Buffer
+
+		_, err = f.contents.WriteToFileContext(ctx, p.g.FS, f.path, &b)
+		if err != nil {
+			return false, err
+		}
+
+		if !bytes.Equal(existing, b.Bytes()) {
+			return false, nil
+		}
+	}
+
+	changed, err := attributesChanged(p.g.FS, f)
+
+	return !changed, err
+}
+
+func (p *planner) unmodified(path string) bool {
+	if p.manifest == nil || !p.manifest.Generated(path) {
+		return false
+	}
+
+	existing, err := p.g.FS.ReadFile(path)
+	if err != nil {
+		return false
+	}
+
+	return p.manifest.Unmodified(path, existing)
+}
+
+func (p *planner) planManifest() error {
+	dirs := []string{}
+	for dir := path.Dir(p.g.manifestPath()); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		dirs = append(dirs, dir)
+	}
+
+	slices.Reverse(dirs)
+
+	for _, dir := range dirs {
+		exists, err := p.exists(dir)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			p.add(OpMkdir, dir)
+		}
+	}
+
+	return p.planWrite(p.g.manifestPath())
+}
+
+// planWrite plans to create path, or to overwrite it if it exists.
+func (p *planner) planWrite(path string) error {
+	exists, err := p.exists(path)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		p.add(OpOverwrite, path)
+	} else {
+		p.add(OpCreate, path)
+	}
+
+	return nil
+}
+
+// planConflict plans an existing file. Only a [Resolution] can be planned, files of any
+// other [ConflictResolver] are planned to be overwritten.
+func (p *planner) planConflict(f *genfile) error {
+	resolution, ok := p.g.ConflictResolver.(Resolution)
+	if !ok {
+		p.add(OpOverwrite, f.path)
+		return nil
+	}
+
+	switch resolution {
+	case ConflictOverwrite:
+		p.add(OpOverwrite, f.path)
+	case ConflictSkip:
+	case ConflictKeepBoth:
+		// an existing file with the suffix is overwritten, like [FSGenerator.resolveConflict] does
+		return p.planWrite(f.path + keepBothSuffix)
+	case ConflictBackup:
+		// the backup is always written, like [FSGenerator.backupFile] does
+		if err := p.planWrite(f.path + backupSuffix); err != nil {
+			return err
+		}
+
+		p.add(OpOverwrite, f.path)
+	case ConflictFail:
+		return fmt.Errorf("%w: %s: %w", ErrConflict, f.path, fs.ErrExist)
+	}
+
+	return nil
+}
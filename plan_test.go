@@ -0,0 +1,97 @@
+package drydock
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFSGenerator_Plan(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmpdir := WritableMapFS{
+		"README.md":   &fstest.MapFile{Data: []byte("old readme")},
+		"config.json": &fstest.MapFile{Data: []byte(`{}`)},
+		"pkg":         &fstest.MapFile{Mode: fs.ModeDir | 0755},
+	}
+
+	g := &FSGenerator{FS: tmpdir}
+
+	plan, err := g.Plan(ctx,
+		PlainFile("README.md", "This is the package"),
+		ModifyFile("config.json", json.Unmarshal, func(c *map[string]any) ([]byte, error) {
+			return json.Marshal(c)
+		}),
+		Dir("bin",
+			Dir("cli",
+				PlainFile("main.go", "package main"),
+			),
+		),
+		Dir("pkg",
+			PlainFile("README.md", "how to use this thing"),
+		),
+	)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []Operation{
+		{Op: OpMkdir, Path: "bin"},
+		{Op: OpMkdir, Path: "bin/cli"},
+		{Op: OpOverwrite, Path: "README.md"},
+		{Op: OpModify, Path: "config.json"},
+		{Op: OpCreate, Path: "bin/cli/main.go"},
+		{Op: OpCreate, Path: "pkg/README.md"},
+	}, plan.Operations)
+
+	assert.Equal(t, 2, plan.Count(OpCreate))
+	assert.Equal(t, 1, plan.Count(OpOverwrite))
+
+	assert.Len(t, tmpdir, 3, "plan must not write to the FS")
+	readme, err := tmpdir.ReadFile("README.md")
+	assert.NoError(t, err)
+	assert.Equal(t, "old readme", string(readme))
+}
+
+func TestFSGenerator_Plan_CleanDir(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmpdir := WritableMapFS{
+		"README.md": &fstest.MapFile{Data: []byte("old readme")},
+		"pkg":       &fstest.MapFile{Mode: fs.ModeDir | 0755},
+	}
+
+	g := &FSGenerator{FS: tmpdir, CleanDir: true, ErrorOnExistingFile: true}
+
+	plan, err := g.Plan(ctx, PlainFile("README.md", "new readme"), Dir("pkg"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "remove README.md\nremove pkg\nmkdir pkg\ncreate README.md\n", plan.String())
+}
+
+func TestFSGenerator_Plan_ErrorOnExisting(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmpdir := WritableMapFS{
+		"README.md": &fstest.MapFile{Data: []byte("old readme")},
+		"pkg":       &fstest.MapFile{Mode: fs.ModeDir | 0755},
+	}
+
+	g := &FSGenerator{FS: tmpdir, ErrorOnExistingFile: true}
+
+	_, err := g.Plan(ctx, PlainFile("README.md", "new readme"))
+	assert.ErrorIs(t, err, fs.ErrExist)
+
+	g = &FSGenerator{FS: tmpdir, ErrorOnExistingDir: true}
+
+	_, err = g.Plan(ctx, Dir("pkg"))
+	assert.ErrorIs(t, err, fs.ErrExist)
+
+	_, err = g.Plan(ctx, Dir("created_twice"), Dir("created_twice"))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,253 @@
+package drydock
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// staleFiles returns the files generated by the previous run of this blueprint which are no
+// longer part of the current file tree, sorted by path.
+func staleFiles(prev *Manifest, blueprint string, current map[string]struct{}) []string {
+	stale := []string{}
+
+	for p, f := range prev.Files {
+		if f.Blueprint != blueprint {
+			continue
+		}
+
+		if _, ok := current[p]; !ok {
+			stale = append(stale, p)
+		}
+	}
+
+	slices.Sort(stale)
+
+	return stale
+}
+
+// sortDeepestFirst sorts dirs so that subdirectories come before their parents.
+func sortDeepestFirst(dirs []string) {
+	slices.SortFunc(dirs, func(a, b string) int {
+		if d := strings.Count(b, "/") - strings.Count(a, "/"); d != 0 {
+			return d
+		}
+
+		return strings.Compare(a, b)
+	})
+}
+
+func (g *FSGenerator) prune(gendirs []*gendir, genfiles []*genfile) error {
+	if !g.Prune || g.manifests == nil {
+		return nil
+	}
+
+	current := make(map[string]struct{}, len(gendirs)+len(genfiles)+1)
+	current[g.manifestPath()] = struct{}{}
+
+	for _, d := range gendirs {
+		current[d.path] = struct{}{}
+	}
+
+	for _, f := range genfiles {
+		current[f.path] = struct{}{}
+	}
+
+	parents := []string{}
+
+	for _, p := range staleFiles(g.manifests.prev, g.Blueprint, current) {
+		removed, err := g.pruneFile(p)
+		if err != nil {
+			err = g.failed("remove", p, err)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if removed {
+			parents = append(parents, path.Dir(p))
+		}
+	}
+
+	sortDeepestFirst(parents)
+
+	for _, dir := range parents {
+		err := g.pruneDir(dir, current)
+		if err != nil {
+			err = g.failed("remove", dir, err)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// pruneFile removes p if it was not edited since it was generated.
+func (g *FSGenerator) pruneFile(p string) (bool, error) {
+	start := time.Now()
+
+	contents, err := g.fsys.ReadFile(p)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	if !g.manifests.prev.Unmodified(p, contents) {
+		g.skipped(p)
+		return false, nil
+	}
+
+	stat, err := statFile(g.fsys, p)
+	if err != nil {
+		return false, err
+	}
+
+	err = g.fsys.Remove(p)
+	if err != nil {
+		return false, err
+	}
+
+	if g.journal != nil {
+		g.journal.removed(p, false, contents, stat.Mode().Perm())
+	}
+
+	g.removed(p, false, start)
+
+	return true, nil
+}
+
+// pruneDir removes dir and its parents, as long as they are empty and not part of the current
+// file tree.
+func (g *FSGenerator) pruneDir(dir string, current map[string]struct{}) error {
+	start := time.Now()
+
+	if dir == "." {
+		return nil
+	}
+
+	if _, ok := current[dir]; ok {
+		return nil
+	}
+
+	entries, err := fs.ReadDir(g.fsys, dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+
+	if len(entries) != 0 {
+		return nil
+	}
+
+	stat, err := statFile(g.fsys, dir)
+	if err != nil {
+		return err
+	}
+
+	err = g.fsys.Remove(dir)
+	if err != nil {
+		return err
+	}
+
+	if g.journal != nil {
+		g.journal.removed(dir, true, nil, stat.Mode().Perm())
+	}
+
+	g.removed(dir, true, start)
+
+	return g.pruneDir(path.Dir(dir), current)
+}
+
+func (g *FSGenerator) removed(p string, isDir bool, start time.Time) {
+	g.report.add(ReportEntry{Path: p, IsDir: isDir, Action: ActionRemoved, Duration: time.Since(start)})
+	g.observer().Removed(p)
+}
+
+func (p *planner) planPrune(gendirs []*gendir, genfiles []*genfile) error {
+	if !p.g.Prune || p.manifest == nil {
+		return nil
+	}
+
+	current := make(map[string]struct{}, len(gendirs)+len(genfiles)+1)
+	current[p.g.manifestPath()] = struct{}{}
+
+	for _, d := range gendirs {
+		current[d.path] = struct{}{}
+	}
+
+	for _, f := range genfiles {
+		current[f.path] = struct{}{}
+	}
+
+	removed := map[string]struct{}{}
+	parents := []string{}
+
+	for _, stale := range staleFiles(p.manifest, p.g.Blueprint, current) {
+		contents, err := p.g.FS.ReadFile(stale)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+
+			return err
+		}
+
+		if p.manifest.Unmodified(stale, contents) {
+			p.plan.Operations = append(p.plan.Operations, Operation{Op: OpRemove, Path: stale})
+			removed[stale] = struct{}{}
+			parents = append(parents, path.Dir(stale))
+		}
+	}
+
+	sortDeepestFirst(parents)
+
+	for _, dir := range parents {
+		err := p.planPruneDir(dir, current, removed)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *planner) planPruneDir(dir string, current map[string]struct{}, removed map[string]struct{}) error {
+	if dir == "." {
+		return nil
+	}
+
+	_, isCurrent := current[dir]
+	_, isRemoved := removed[dir]
+	if isCurrent || isRemoved {
+		return nil
+	}
+
+	entries, err := fs.ReadDir(p.g.FS, dir)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		if _, ok := removed[path.Join(dir, e.Name())]; !ok {
+			return nil
+		}
+	}
+
+	p.plan.Operations = append(p.plan.Operations, Operation{Op: OpRemove, Path: dir})
+	removed[dir] = struct{}{}
+
+	return p.planPruneDir(path.Dir(dir), current, removed)
+}
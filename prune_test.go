@@ -0,0 +1,104 @@
+package drydock
+
+import (
+	"context"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFSGenerator_Generate_Prune(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmpdir := WritableMapFS{
+		"hand-written.txt": &fstest.MapFile{Data: []byte("hand written")},
+	}
+
+	g := &FSGenerator{FS: tmpdir, Prune: true}
+
+	err := g.Generate(ctx,
+		PlainFile("README.md", "readme"),
+		Dir("pkg",
+			PlainFile("a.go", "package pkg"),
+			Dir("sub", PlainFile("b.go", "package sub")),
+		),
+		Dir("edited", PlainFile("c.go", "package edited")),
+	)
+	assert.NoError(t, err)
+
+	tmpdir["edited/c.go"].Data = []byte("package edited // by hand")
+
+	plan, err := g.Plan(ctx, PlainFile("README.md", "readme"))
+	assert.NoError(t, err)
+	assert.Equal(t, `overwrite README.md
+remove pkg/a.go
+remove pkg/sub/b.go
+remove pkg/sub
+remove pkg
+overwrite .drydock/manifest.json
+`, plan.String())
+
+	report, err := g.GenerateWithReport(ctx, PlainFile("README.md", "readme"))
+	assert.NoError(t, err)
+	assert.Equal(t, 4, report.Count(ActionRemoved))
+
+	actual := []string{}
+	for name := range tmpdir {
+		actual = append(actual, name)
+	}
+
+	assert.ElementsMatch(t, []string{
+		"hand-written.txt",
+		"README.md",
+		"edited",
+		"edited/c.go",
+		".drydock",
+		".drydock/manifest.json",
+	}, actual)
+
+	manifest, err := ReadManifest(tmpdir, DefaultManifestPath)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]ManifestFile{
+		"README.md": {SHA256: hashContents([]byte("readme"))},
+	}, manifest.Files)
+}
+
+func TestFSGenerator_Generate_Prune_Transactional(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmpdir := WritableMapFS{}
+
+	g := &FSGenerator{FS: tmpdir, Prune: true, Transactional: true}
+
+	err := g.Generate(ctx, Dir("pkg", PlainFile("a.go", "package pkg")))
+	assert.NoError(t, err)
+
+	g.FS = &failingRenameFS{WritableMapFS: tmpdir, path: DefaultManifestPath}
+
+	err = g.Generate(ctx, PlainFile("README.md", "readme"))
+	assert.ErrorIs(t, err, errFailingFile)
+
+	contents, err := tmpdir.ReadFile("pkg/a.go")
+	assert.NoError(t, err)
+	assert.Equal(t, "package pkg", string(contents))
+
+	_, err = tmpdir.ReadFile("README.md")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+type failingRenameFS struct {
+	WritableMapFS
+	path string
+}
+
+func (f *failingRenameFS) Rename(oldpath string, newpath string) error {
+	if newpath == f.path {
+		return errFailingFile
+	}
+
+	return f.WritableMapFS.Rename(oldpath, newpath)
+}
@@ -9,17 +9,22 @@ func Render(files ...File) string {
 
 	var dir Directory
 
+	files = Flatten(files...)
+
 	if len(files) == 1 {
 		if d, isDir := files[0].(Directory); isDir {
 			dir = d
 		}
-	} else {
+	}
+
+	if dir == nil {
 		dir = Dir(".", files...)
 	}
 
 	b.WriteString(dir.Name() + "\n")
 
 	entries, _ := dir.Entries()
+	entries = Flatten(entries...)
 
 	for i, entry := range entries {
 		isLast := i == len(entries)-1
@@ -27,7 +32,7 @@ func Render(files ...File) string {
 			renderDir(&b, dir, 0, isLast)
 		} else {
 			prefix(&b, 0, false, isLast)
-			b.WriteString(entry.Name() + "\n")
+			b.WriteString(fileName(entry) + "\n")
 		}
 	}
 
@@ -36,9 +41,10 @@ func Render(files ...File) string {
 
 func renderDir(b *strings.Builder, dir Directory, level int, isLast bool) {
 	prefix(b, level, isLast, isLast)
-	b.WriteString(dir.Name() + "/\n")
+	b.WriteString(dirName(dir) + "\n")
 
 	entries, _ := dir.Entries()
+	entries = Flatten(entries...)
 
 	for i, entry := range entries {
 		lastEntry := i == len(entries)-1
@@ -46,11 +52,27 @@ func renderDir(b *strings.Builder, dir Directory, level int, isLast bool) {
 			renderDir(b, dir, level+1, lastEntry)
 		} else {
 			prefix(b, level+1, isLast, lastEntry)
-			b.WriteString(entry.Name() + "\n")
+			b.WriteString(fileName(entry) + "\n")
 		}
 	}
 }
 
+func dirName(d Directory) string {
+	if isLazy(d) {
+		return d.Name() + "/ (lazy)"
+	}
+
+	return d.Name() + "/"
+}
+
+func fileName(f File) string {
+	if link, ok := f.(SymlinkFile); ok {
+		return f.Name() + " -> " + link.Target()
+	}
+
+	return f.Name()
+}
+
 func prefix(b *strings.Builder, level int, parentIsLast bool, isLast bool) {
 	for i := 0; i < level; i++ {
 		if parentIsLast {
@@ -20,6 +20,15 @@ func TestRender(t *testing.T) {
 ├── fileA
 ├── fileB
 └── fileC
+`,
+		},
+		{
+			name:  "Symlinks",
+			input: []File{PlainFile("fileA", ""), Dir("dirA", Symlink("fileA", "../fileA"))},
+			exp: `.
+├── fileA
+└── dirA/
+    └── fileA -> ../fileA
 `,
 		},
 		{
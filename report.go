@@ -0,0 +1,95 @@
+package drydock
+
+import (
+	"fmt"
+	"time"
+)
+
+// Action is what [FSGenerator.Generate] did with a path.
+type Action int
+
+const (
+	// ActionCreated is reported for new files and directories.
+	ActionCreated Action = iota + 1
+	// ActionOverwritten is reported for existing files which were replaced.
+	ActionOverwritten
+	// ActionModified is reported for existing files which were updated, e.g. by [ModifyFile].
+	ActionModified
+	// ActionSkipped is reported for files which were not written.
+	ActionSkipped
+	// ActionUnchanged is reported for paths which already existed and were left untouched,
+	// like existing directories.
+	ActionUnchanged
+	// ActionRemoved is reported for files and directories which were removed.
+	ActionRemoved
+)
+
+func (a Action) String() string {
+	switch a {
+	case ActionCreated:
+		return "created"
+	case ActionOverwritten:
+		return "overwritten"
+	case ActionModified:
+		return "modified"
+	case ActionSkipped:
+		return "skipped"
+	case ActionUnchanged:
+		return "unchanged"
+	case ActionRemoved:
+		return "removed"
+	default:
+		return fmt.Sprintf("Action(%d)", int(a))
+	}
+}
+
+// ReportEntry describes what happened to a single path.
+type ReportEntry struct {
+	Path     string
+	IsDir    bool
+	Action   Action
+	Bytes    int64
+	Duration time.Duration
+}
+
+// Report lists every path touched by [FSGenerator.GenerateWithReport] in the order they were processed.
+type Report struct {
+	Entries []ReportEntry
+}
+
+// Count returns the number of entries with action a.
+func (r *Report) Count(a Action) int {
+	count := 0
+	for _, e := range r.Entries {
+		if e.Action == a {
+			count++
+		}
+	}
+
+	return count
+}
+
+// BytesWritten returns the sum of all bytes written.
+func (r *Report) BytesWritten() int64 {
+	var n int64
+	for _, e := range r.Entries {
+		n += e.Bytes
+	}
+
+	return n
+}
+
+func (r *Report) add(e ReportEntry) {
+	r.Entries = append(r.Entries, e)
+}
+
+func fileAction(existed bool, isNewFile bool) Action {
+	switch {
+	case !existed:
+		return ActionCreated
+	case !isNewFile:
+		return ActionModified
+	default:
+		return ActionOverwritten
+	}
+}
@@ -0,0 +1,81 @@
+package drydock
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type nameOnlyFile string
+
+func (f nameOnlyFile) Name() string {
+	return string(f)
+}
+
+func TestFSGenerator_GenerateWithReport(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmpdir := WritableMapFS{
+		"README.md":   &fstest.MapFile{Data: []byte("old readme")},
+		"config.json": &fstest.MapFile{Data: []byte(`{}`)},
+		"pkg":         &fstest.MapFile{Mode: fs.ModeDir | 0755},
+	}
+
+	g := &FSGenerator{FS: tmpdir}
+
+	report, err := g.GenerateWithReport(ctx,
+		PlainFile("README.md", "new readme"),
+		ModifyFile("config.json", json.Unmarshal, func(c *map[string]string) ([]byte, error) {
+			(*c)["foo"] = "bar"
+			return json.Marshal(c)
+		}),
+		Dir("bin", TemplateFile("main.go", "package {{ . }}", "main")),
+		Dir("pkg", nameOnlyFile("skipped")),
+	)
+	assert.NoError(t, err)
+
+	type entry struct {
+		Path   string
+		IsDir  bool
+		Action Action
+		Bytes  int64
+	}
+
+	actual := make([]entry, 0, len(report.Entries))
+	for _, e := range report.Entries {
+		actual = append(actual, entry{Path: e.Path, IsDir: e.IsDir, Action: e.Action, Bytes: e.Bytes})
+	}
+
+	assert.Equal(t, []entry{
+		{Path: "bin", IsDir: true, Action: ActionCreated},
+		{Path: "pkg", IsDir: true, Action: ActionUnchanged},
+		{Path: "README.md", Action: ActionOverwritten, Bytes: 10},
+		{Path: "config.json", Action: ActionModified, Bytes: 13},
+		{Path: "bin/main.go", Action: ActionCreated, Bytes: 12},
+		{Path: "pkg/skipped", Action: ActionSkipped},
+	}, actual)
+
+	assert.Equal(t, 2, report.Count(ActionCreated))
+	assert.Equal(t, int64(35), report.BytesWritten())
+}
+
+func TestFSGenerator_GenerateWithReport_Error(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	g := &FSGenerator{FS: WritableMapFS{}}
+
+	report, err := g.GenerateWithReport(ctx,
+		PlainFile("a", "a"),
+		&erroringFile{name: "b", err: errFailingFile},
+		PlainFile("c", "c"),
+	)
+	assert.ErrorIs(t, err, errFailingFile)
+	assert.Len(t, report.Entries, 1)
+	assert.Equal(t, "a", report.Entries[0].Path)
+}
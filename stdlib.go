@@ -0,0 +1,274 @@
+package drydock
+
+import "slices"
+
+// stdlibPackages maps the names of standard library packages to their import paths. Names
+// shared by multiple packages are listed in [ambiguousStdlibPackages] instead. Only packages
+// available in Go 1.22, the minimum version of this module, are listed.
+var stdlibPackages = map[string]string{
+	"adler32":         "hash/adler32",
+	"aes":             "crypto/aes",
+	"ascii85":         "encoding/ascii85",
+	"asn1":            "encoding/asn1",
+	"ast":             "go/ast",
+	"atomic":          "sync/atomic",
+	"base32":          "encoding/base32",
+	"base64":          "encoding/base64",
+	"big":             "math/big",
+	"binary":          "encoding/binary",
+	"bits":            "math/bits",
+	"bufio":           "bufio",
+	"build":           "go/build",
+	"buildinfo":       "debug/buildinfo",
+	"bytes":           "bytes",
+	"bzip2":           "compress/bzip2",
+	"cgi":             "net/http/cgi",
+	"cgo":             "runtime/cgo",
+	"cipher":          "crypto/cipher",
+	"cmp":             "cmp",
+	"cmplx":           "math/cmplx",
+	"color":           "image/color",
+	"comment":         "go/doc/comment",
+	"constant":        "go/constant",
+	"constraint":      "go/build/constraint",
+	"context":         "context",
+	"cookiejar":       "net/http/cookiejar",
+	"coverage":        "runtime/coverage",
+	"crc32":           "hash/crc32",
+	"crc64":           "hash/crc64",
+	"crypto":          "crypto",
+	"csv":             "encoding/csv",
+	"debug":           "runtime/debug",
+	"des":             "crypto/des",
+	"doc":             "go/doc",
+	"draw":            "image/draw",
+	"driver":          "database/sql/driver",
+	"dsa":             "crypto/dsa",
+	"dwarf":           "debug/dwarf",
+	"ecdh":            "crypto/ecdh",
+	"ecdsa":           "crypto/ecdsa",
+	"ed25519":         "crypto/ed25519",
+	"elf":             "debug/elf",
+	"elliptic":        "crypto/elliptic",
+	"embed":           "embed",
+	"encoding":        "encoding",
+	"errors":          "errors",
+	"exec":            "os/exec",
+	"expvar":          "expvar",
+	"fcgi":            "net/http/fcgi",
+	"filepath":        "path/filepath",
+	"flag":            "flag",
+	"flate":           "compress/flate",
+	"fmt":             "fmt",
+	"fnv":             "hash/fnv",
+	"format":          "go/format",
+	"fs":              "io/fs",
+	"fstest":          "testing/fstest",
+	"gif":             "image/gif",
+	"gob":             "encoding/gob",
+	"gosym":           "debug/gosym",
+	"gzip":            "compress/gzip",
+	"hash":            "hash",
+	"heap":            "container/heap",
+	"hex":             "encoding/hex",
+	"hmac":            "crypto/hmac",
+	"html":            "html",
+	"http":            "net/http",
+	"httptest":        "net/http/httptest",
+	"httptrace":       "net/http/httptrace",
+	"httputil":        "net/http/httputil",
+	"image":           "image",
+	"importer":        "go/importer",
+	"io":              "io",
+	"iotest":          "testing/iotest",
+	"ioutil":          "io/ioutil",
+	"jpeg":            "image/jpeg",
+	"json":            "encoding/json",
+	"jsonrpc":         "net/rpc/jsonrpc",
+	"list":            "container/list",
+	"log":             "log",
+	"lzw":             "compress/lzw",
+	"macho":           "debug/macho",
+	"mail":            "net/mail",
+	"maphash":         "hash/maphash",
+	"maps":            "maps",
+	"math":            "math",
+	"md5":             "crypto/md5",
+	"metrics":         "runtime/metrics",
+	"mime":            "mime",
+	"multipart":       "mime/multipart",
+	"net":             "net",
+	"netip":           "net/netip",
+	"os":              "os",
+	"palette":         "image/color/palette",
+	"parse":           "text/template/parse",
+	"parser":          "go/parser",
+	"path":            "path",
+	"pe":              "debug/pe",
+	"pem":             "encoding/pem",
+	"pkix":            "crypto/x509/pkix",
+	"plan9obj":        "debug/plan9obj",
+	"plugin":          "plugin",
+	"png":             "image/png",
+	"printer":         "go/printer",
+	"quick":           "testing/quick",
+	"quotedprintable": "mime/quotedprintable",
+	"race":            "runtime/race",
+	"rc4":             "crypto/rc4",
+	"reflect":         "reflect",
+	"regexp":          "regexp",
+	"ring":            "container/ring",
+	"rpc":             "net/rpc",
+	"rsa":             "crypto/rsa",
+	"runtime":         "runtime",
+	"sha1":            "crypto/sha1",
+	"sha256":          "crypto/sha256",
+	"sha512":          "crypto/sha512",
+	"signal":          "os/signal",
+	"slices":          "slices",
+	"slog":            "log/slog",
+	"slogtest":        "testing/slogtest",
+	"smtp":            "net/smtp",
+	"sort":            "sort",
+	"sql":             "database/sql",
+	"strconv":         "strconv",
+	"strings":         "strings",
+	"subtle":          "crypto/subtle",
+	"suffixarray":     "index/suffixarray",
+	"sync":            "sync",
+	"syntax":          "regexp/syntax",
+	"syscall":         "syscall",
+	"syslog":          "log/syslog",
+	"tabwriter":       "text/tabwriter",
+	"tar":             "archive/tar",
+	"testing":         "testing",
+	"textproto":       "net/textproto",
+	"time":            "time",
+	"tls":             "crypto/tls",
+	"token":           "go/token",
+	"trace":           "runtime/trace",
+	"types":           "go/types",
+	"tzdata":          "time/tzdata",
+	"unicode":         "unicode",
+	"unsafe":          "unsafe",
+	"url":             "net/url",
+	"user":            "os/user",
+	"utf16":           "unicode/utf16",
+	"utf8":            "unicode/utf8",
+	"version":         "go/version",
+	"x509":            "crypto/x509",
+	"xml":             "encoding/xml",
+	"zip":             "archive/zip",
+	"zlib":            "compress/zlib",
+}
+
+// stdlibCandidate is one of multiple standard library packages sharing a name.
+type stdlibCandidate struct {
+	path    string
+	symbols []string
+}
+
+// ambiguousStdlibPackages lists the exported symbols of standard library packages which share
+// their name, ordered by how commonly they are used.
+var ambiguousStdlibPackages = map[string][]stdlibCandidate{
+	"pprof": {
+		{
+			path: "runtime/pprof",
+			symbols: []string{
+				"Do", "ForLabels", "Label", "LabelSet", "Labels", "Lookup", "NewProfile", "Profile",
+				"Profiles", "SetGoroutineLabels", "StartCPUProfile", "StopCPUProfile", "WithLabels",
+				"WriteHeapProfile",
+			},
+		},
+		{
+			path:    "net/http/pprof",
+			symbols: []string{"Cmdline", "Handler", "Index", "Profile", "Symbol", "Trace"},
+		},
+	},
+	"rand": {
+		{
+			path: "math/rand",
+			symbols: []string{
+				"ExpFloat64", "Float32", "Float64", "Int", "Int31", "Int31n", "Int63", "Int63n", "Intn",
+				"New", "NewSource", "NewZipf", "NormFloat64", "Perm", "Rand", "Read", "Seed", "Shuffle",
+				"Source", "Source64", "Uint32", "Uint64", "Zipf",
+			},
+		},
+		{
+			path:    "crypto/rand",
+			symbols: []string{"Int", "Prime", "Read", "Reader", "Text"},
+		},
+		{
+			path: "math/rand/v2",
+			symbols: []string{
+				"ChaCha8", "ExpFloat64", "Float32", "Float64", "Int", "Int32", "Int32N", "Int64", "Int64N",
+				"IntN", "N", "New", "NewChaCha8", "NewPCG", "NewZipf", "NormFloat64", "PCG", "Perm", "Rand",
+				"Shuffle", "Source", "Uint", "Uint32", "Uint32N", "Uint64", "Uint64N", "UintN", "Zipf",
+			},
+		},
+	},
+	"scanner": {
+		{
+			path: "text/scanner",
+			symbols: []string{
+				"Char", "Comment", "EOF", "Float", "GoTokens", "GoWhitespace", "Ident", "Int", "Position",
+				"RawString", "ScanChars", "ScanComments", "ScanFloats", "ScanIdents", "ScanInts",
+				"ScanRawStrings", "ScanStrings", "Scanner", "SkipComments", "String", "TokenString",
+			},
+		},
+		{
+			path:    "go/scanner",
+			symbols: []string{"Error", "ErrorHandler", "ErrorList", "Mode", "PrintError", "ScanComments", "Scanner"},
+		},
+	},
+	"template": {
+		{
+			path: "text/template",
+			symbols: []string{
+				"ExecError", "FuncMap", "HTMLEscape", "HTMLEscapeString", "HTMLEscaper", "IsTrue",
+				"JSEscape", "JSEscapeString", "JSEscaper", "Must", "New", "ParseFS", "ParseFiles",
+				"ParseGlob", "Template", "URLQueryEscaper",
+			},
+		},
+		{
+			path: "html/template",
+			symbols: []string{
+				"CSS", "ErrAmbigContext", "ErrBadHTML", "ErrBranchEnd", "ErrEndContext", "ErrJSTemplate",
+				"ErrNoSuchTemplate", "ErrOutputContext", "ErrPartialCharset", "ErrPartialEscape",
+				"ErrPredefinedEscaper", "ErrRangeLoopReentry", "ErrSlashAmbig", "Error", "ErrorCode",
+				"FuncMap", "HTML", "HTMLAttr", "HTMLEscape", "HTMLEscapeString", "HTMLEscaper", "IsTrue",
+				"JS", "JSEscape", "JSEscapeString", "JSEscaper", "JSStr", "Must", "New", "OK", "ParseFS",
+				"ParseFiles", "ParseGlob", "Srcset", "Template", "URL", "URLQueryEscaper",
+			},
+		},
+	},
+}
+
+// stdlibPackage returns the import path of the standard library package called name. If multiple
+// packages share the name, the first one declaring all selectors is used. If none does, the
+// package is not found rather than guessed.
+func stdlibPackage(name string, selectors map[string]struct{}) (string, bool) {
+	candidates, ok := ambiguousStdlibPackages[name]
+	if !ok {
+		p, ok := stdlibPackages[name]
+		return p, ok
+	}
+
+	for _, c := range candidates {
+		if declaresAll(c.symbols, selectors) {
+			return c.path, true
+		}
+	}
+
+	return "", false
+}
+
+func declaresAll(symbols []string, selectors map[string]struct{}) bool {
+	for sel := range selectors {
+		if !slices.Contains(symbols, sel) {
+			return false
+		}
+	}
+
+	return true
+}
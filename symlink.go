@@ -0,0 +1,210 @@
+package drydock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"time"
+)
+
+// SymlinkFS is an optional capability of a [WritableFS] to create symbolic links.
+type SymlinkFS interface {
+	WritableFS
+
+	// Symlink should behave like [os.Symlink].
+	Symlink(oldname string, newname string) error
+
+	// Readlink should behave like [os.Readlink].
+	Readlink(name string) (string, error)
+}
+
+// SymlinkFile is a [File] which is generated as a symbolic link to Target.
+type SymlinkFile interface {
+	File
+	Target() string
+}
+
+// Symlink creates a symbolic link called name pointing to target. target is written as is,
+// so relative targets are relative to the directory of the link. Generating symlinks requires
+// a [WritableFS] implementing [SymlinkFS]. An existing regular file is only replaced according to
+// [FSGenerator.ErrorOnExistingFile] and [FSGenerator.ConflictResolver], like it would be by
+// any other file.
+func Symlink(name string, target string) File {
+	return &symlink{name: name, target: target}
+}
+
+type symlink struct {
+	name   string
+	target string
+}
+
+func (s *symlink) Name() string {
+	return s.name
+}
+
+// Target implements [SymlinkFile].
+func (s *symlink) Target() string {
+	return s.target
+}
+
+// readlink returns the target of the symlink name. If name is not a symlink ok is false.
+func readlink(fsys WritableFS, name string) (target string, ok bool, err error) {
+	symlinkFS, isSymlinkFS := fsys.(SymlinkFS)
+	if !isSymlinkFS {
+		return "", false, fmt.Errorf("can't read symlink %s: %w", name, errors.ErrUnsupported)
+	}
+
+	target, err = symlinkFS.Readlink(name)
+	if err != nil {
+		if errors.Is(err, errors.ErrUnsupported) {
+			return "", false, fmt.Errorf("can't read symlink %s: %w", name, err)
+		}
+
+		// name doesn't exist or is not a symlink
+		return "", false, nil
+	}
+
+	return target, true, nil
+}
+
+func createSymlink(fsys WritableFS, target string, name string) error {
+	symlinkFS, ok := fsys.(SymlinkFS)
+	if !ok {
+		return fmt.Errorf("can't create symlink %s: %w", name, errors.ErrUnsupported)
+	}
+
+	return symlinkFS.Symlink(target, name)
+}
+
+func (g *FSGenerator) generateSymlink(ctx context.Context, file *genfile) error {
+	start := time.Now()
+
+	prevTarget, isSymlink, err := readlink(g.fsys, file.path)
+	if err != nil {
+		return err
+	}
+
+	if isSymlink && prevTarget == file.symlink {
+		g.report.add(ReportEntry{Path: file.path, Action: ActionUnchanged, Duration: time.Since(start)})
+		g.observer().FileSkipped(file.path)
+		return nil
+	}
+
+	existed := isSymlink
+	if !existed {
+		existed, err = fileExists(g.fsys, file.path)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case existed && !isSymlink:
+		// a regular file is replaced like it would be by a regular file
+		file, existed, err = g.checkExisting(ctx, file)
+		if err != nil || file == nil {
+			return err
+		}
+
+		prevTarget, isSymlink, err = readlink(g.fsys, file.path)
+		if err != nil {
+			return err
+		}
+	case existed && g.ErrorOnExistingFile:
+		return fmt.Errorf("file already exits %s: %w", file.path, fs.ErrExist)
+	}
+
+	g.observer().FileWriting(file.path)
+
+	if existed {
+		err = g.removeForSymlink(file.path, prevTarget, isSymlink)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = createSymlink(g.fsys, file.symlink, file.path)
+	if err != nil {
+		return err
+	}
+
+	if g.journal != nil {
+		g.journal.created(file.path, false)
+	}
+
+	entry := ReportEntry{Path: file.path, Action: fileAction(existed, true), Duration: time.Since(start)}
+
+	g.report.add(entry)
+	g.observer().FileWritten(entry)
+
+	return nil
+}
+
+// removeForSymlink removes the existing file p, so it can be replaced by a symlink.
+func (g *FSGenerator) removeForSymlink(p string, prevTarget string, isSymlink bool) error {
+	var backup []byte
+	var mode fs.FileMode
+
+	if g.journal != nil && !isSymlink {
+		var err error
+
+		backup, mode, err = g.readBackup(p)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := g.fsys.Remove(p)
+	if err != nil {
+		return err
+	}
+
+	if g.journal != nil {
+		if isSymlink {
+			g.journal.removedSymlink(p, prevTarget)
+		} else {
+			g.journal.removed(p, false, backup, mode)
+		}
+	}
+
+	return nil
+}
+
+func (p *planner) planSymlink(f *genfile) error {
+	exists, err := p.exists(f.path)
+	if err != nil {
+		return err
+	}
+
+	var isSymlink bool
+
+	if _, planned := p.planned[f.path]; !planned && !p.cleaned {
+		var prevTarget string
+
+		prevTarget, isSymlink, err = readlink(p.g.FS, f.path)
+		if err != nil {
+			return err
+		}
+
+		if isSymlink && prevTarget == f.symlink {
+			return nil
+		}
+
+		// dangling symlinks don't exist according to stat
+		exists = exists || isSymlink
+	}
+
+	switch {
+	case !exists:
+		p.add(OpCreate, f.path)
+	case p.g.ErrorOnExistingFile:
+		return fmt.Errorf("file already exits %s: %w", f.path, fs.ErrExist)
+	case isSymlink, p.unmodified(f.path):
+		p.add(OpOverwrite, f.path)
+	default:
+		return p.planConflict(f)
+	}
+
+	return nil
+}
@@ -0,0 +1,208 @@
+package drydock
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFSGenerator_Generate_Symlink(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmpdir := WritableMapFS{}
+
+	g := &FSGenerator{FS: tmpdir}
+
+	report, err := g.GenerateWithReport(ctx,
+		PlainFile(".golangci.yml", "linters: {}"),
+		Dir("service", Symlink(".golangci.yml", "../.golangci.yml")),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, ActionCreated, report.Entries[2].Action)
+
+	target, err := tmpdir.Readlink("service/.golangci.yml")
+	assert.NoError(t, err)
+	assert.Equal(t, "../.golangci.yml", target)
+	assert.Equal(t, fs.ModeSymlink, tmpdir["service/.golangci.yml"].Mode.Type())
+
+	report, err = g.GenerateWithReport(ctx, Dir("service", Symlink(".golangci.yml", "../.golangci.yml")))
+	assert.NoError(t, err)
+	assert.Equal(t, ActionUnchanged, report.Entries[1].Action)
+
+	report, err = g.GenerateWithReport(ctx, Dir("service", Symlink(".golangci.yml", "../../.golangci.yml")))
+	assert.NoError(t, err)
+	assert.Equal(t, ActionOverwritten, report.Entries[1].Action)
+
+	target, err = tmpdir.Readlink("service/.golangci.yml")
+	assert.NoError(t, err)
+	assert.Equal(t, "../../.golangci.yml", target)
+}
+
+func TestFSGenerator_Generate_Symlink_Transactional(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmpdir := WritableMapFS{
+		"config.yml": &fstest.MapFile{Data: []byte("config"), Mode: 0600},
+		"link":       &fstest.MapFile{Data: []byte("config.yml"), Mode: fs.ModeSymlink | 0777},
+	}
+
+	g := &FSGenerator{FS: tmpdir, Transactional: true}
+
+	err := g.Generate(ctx,
+		Symlink("config.yml", "../config.yml"),
+		Symlink("link", "other.yml"),
+		&erroringFile{name: "fail", err: errFailingFile},
+	)
+	assert.ErrorIs(t, err, errFailingFile)
+
+	assert.Equal(t, WritableMapFS{
+		"config.yml": &fstest.MapFile{Data: []byte("config"), Mode: 0600},
+		"link":       &fstest.MapFile{Data: []byte("config.yml"), Mode: fs.ModeSymlink | 0777},
+	}, tmpdir)
+}
+
+func TestDirFSGenerator_Generate_Symlink_Transactional(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmpdir := t.TempDir()
+
+	err := os.WriteFile(path.Join(tmpdir, "target.txt"), []byte("target"), 0644)
+	assert.NoError(t, err)
+	assert.NoError(t, os.Symlink("target.txt", path.Join(tmpdir, "link")))
+	assert.NoError(t, os.Symlink("missing.txt", path.Join(tmpdir, "dangling")))
+
+	g := &FSGenerator{FS: NewWritableDirFS(tmpdir), ConflictResolver: ConflictOverwrite, Transactional: true}
+
+	err = g.Generate(ctx,
+		PlainFile("link", "replaced"),
+		PlainFile("dangling", "replaced"),
+		&erroringFile{name: "fail", err: errFailingFile},
+	)
+	assert.ErrorIs(t, err, errFailingFile)
+
+	target, err := os.Readlink(path.Join(tmpdir, "link"))
+	assert.NoError(t, err)
+	assert.Equal(t, "target.txt", target)
+
+	target, err = os.Readlink(path.Join(tmpdir, "dangling"))
+	assert.NoError(t, err)
+	assert.Equal(t, "missing.txt", target)
+
+	contents, err := os.ReadFile(path.Join(tmpdir, "target.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "target", string(contents))
+}
+
+func TestFSGenerator_Generate_Symlink_Conflict(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tt := []struct {
+		name       string
+		resolution Resolution
+		plan       string
+		err        error
+		exp        WritableMapFS
+	}{
+		{
+			name:       "Skip",
+			resolution: ConflictSkip,
+			exp: WritableMapFS{
+				"a.txt": &fstest.MapFile{Data: []byte("hand edited")},
+			},
+		},
+		{
+			name:       "Fail",
+			resolution: ConflictFail,
+			err:        ErrConflict,
+			exp: WritableMapFS{
+				"a.txt": &fstest.MapFile{Data: []byte("hand edited")},
+			},
+		},
+		{
+			name:       "Keep Both",
+			resolution: ConflictKeepBoth,
+			plan:       "create a.txt.new\n",
+			exp: WritableMapFS{
+				"a.txt":     &fstest.MapFile{Data: []byte("hand edited")},
+				"a.txt.new": &fstest.MapFile{Data: []byte("b.txt"), Mode: fs.ModeSymlink | 0777},
+			},
+		},
+		{
+			name:       "Backup",
+			resolution: ConflictBackup,
+			plan:       "create a.txt.orig\noverwrite a.txt\n",
+			exp: WritableMapFS{
+				"a.txt":      &fstest.MapFile{Data: []byte("b.txt"), Mode: fs.ModeSymlink | 0777},
+				"a.txt.orig": &fstest.MapFile{Data: []byte("hand edited"), Mode: 0644},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpdir := WritableMapFS{
+				"a.txt": &fstest.MapFile{Data: []byte("hand edited")},
+			}
+
+			g := &FSGenerator{FS: tmpdir, ConflictResolver: tc.resolution}
+
+			var conflict *Conflict
+
+			plan, err := g.Plan(ctx, Symlink("a.txt", "b.txt"))
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.plan, plan.String())
+			}
+
+			g.ConflictResolver = ConflictResolverFunc(func(_ context.Context, c *Conflict) (Resolution, error) {
+				conflict = c
+				return tc.resolution, nil
+			})
+
+			err = g.Generate(ctx, Symlink("a.txt", "b.txt"))
+			assert.ErrorIs(t, err, tc.err)
+			assert.Equal(t, &Conflict{Path: "a.txt", Existing: []byte("hand edited"), Target: "b.txt"}, conflict)
+
+			for name, file := range tc.exp {
+				assert.Equal(t, string(file.Data), string(tmpdir[name].Data), name)
+				assert.Equal(t, file.Mode, tmpdir[name].Mode, name)
+			}
+
+			assert.Len(t, tmpdir, len(tc.exp))
+		})
+	}
+}
+
+func TestDirFSGenerator_Generate_Symlink(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmpdir := t.TempDir()
+
+	g := &DirFSGenerator{OutputDir: tmpdir}
+
+	err := g.Generate(ctx,
+		PlainFile(".golangci.yml", "linters: {}"),
+		Dir("service", Symlink(".golangci.yml", "../.golangci.yml")),
+	)
+	assert.NoError(t, err)
+
+	target, err := os.Readlink(path.Join(tmpdir, "service", ".golangci.yml"))
+	assert.NoError(t, err)
+	assert.Equal(t, "../.golangci.yml", target)
+
+	contents, err := os.ReadFile(path.Join(tmpdir, "service", ".golangci.yml"))
+	assert.NoError(t, err)
+	assert.Equal(t, "linters: {}", string(contents))
+}
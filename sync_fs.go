@@ -0,0 +1,145 @@
+package drydock
+
+import (
+	"errors"
+	"io/fs"
+	"sync"
+	"time"
+)
+
+// syncFS guards a [WritableFS] with a lock, so it can be used from multiple goroutines,
+// e.g. by workers rendering files while the [FSGenerator] is writing.
+type syncFS struct {
+	fsys WritableFS
+	mu   *sync.RWMutex
+}
+
+func newSyncFS(fsys WritableFS) *syncFS {
+	return &syncFS{fsys: fsys, mu: &sync.RWMutex{}}
+}
+
+func (s *syncFS) Open(name string) (fs.File, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.fsys.Open(name)
+}
+
+func (s *syncFS) ReadFile(name string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.fsys.ReadFile(name)
+}
+
+func (s *syncFS) Stat(name string) (fs.FileInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return fs.Stat(s.fsys, name)
+}
+
+func (s *syncFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return fs.ReadDir(s.fsys, name)
+}
+
+func (s *syncFS) Mkdir(name string, perm fs.FileMode) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fsys.Mkdir(name, perm)
+}
+
+func (s *syncFS) Rename(oldpath string, newpath string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fsys.Rename(oldpath, newpath)
+}
+
+func (s *syncFS) Remove(path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fsys.Remove(path)
+}
+
+func (s *syncFS) RemoveAll(path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fsys.RemoveAll(path)
+}
+
+// Chmod returns [errors.ErrUnsupported] if the wrapped FS does not implement [ChmodFS].
+func (s *syncFS) Chmod(name string, mode fs.FileMode) error {
+	chmodFS, ok := s.fsys.(ChmodFS)
+	if !ok {
+		return errors.ErrUnsupported
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return chmodFS.Chmod(name, mode)
+}
+
+// Chtimes returns [errors.ErrUnsupported] if the wrapped FS does not implement [ChtimesFS].
+func (s *syncFS) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	chtimesFS, ok := s.fsys.(ChtimesFS)
+	if !ok {
+		return errors.ErrUnsupported
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return chtimesFS.Chtimes(name, atime, mtime)
+}
+
+// Symlink returns [errors.ErrUnsupported] if the wrapped FS does not implement [SymlinkFS].
+func (s *syncFS) Symlink(oldname string, newname string) error {
+	symlinkFS, ok := s.fsys.(SymlinkFS)
+	if !ok {
+		return errors.ErrUnsupported
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return symlinkFS.Symlink(oldname, newname)
+}
+
+// Readlink returns [errors.ErrUnsupported] if the wrapped FS does not implement [SymlinkFS].
+func (s *syncFS) Readlink(name string) (string, error) {
+	symlinkFS, ok := s.fsys.(SymlinkFS)
+	if !ok {
+		return "", errors.ErrUnsupported
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return symlinkFS.Readlink(name)
+}
+
+func (s *syncFS) CreateTemp(dir string, pattern string) (WritableFile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := s.fsys.CreateTemp(dir, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	return &syncFile{WritableFile: f, mu: s.mu}, nil
+}
+
+// syncFile guards the Close of a [WritableFile], as closing a file of a [WritableMapFS]
+// updates the file stored in the map.
+type syncFile struct {
+	WritableFile
+	mu *sync.RWMutex
+}
+
+func (f *syncFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.WritableFile.Close()
+}
+
+// Unwrap returns the wrapped file.
+func (f *syncFile) Unwrap() WritableFile {
+	return f.WritableFile
+}
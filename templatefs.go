@@ -0,0 +1,61 @@
+package drydock
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"text/template"
+)
+
+// TemplateSuffix is the suffix of template files in [FromTemplateFS].
+const TemplateSuffix = ".tmpl"
+
+// FromTemplateFS creates a file tree from the directory root in fsys, e.g. an [embed.FS].
+// Every `*.tmpl` file becomes a [FileFromTemplate] executed with data, with the suffix stripped
+// from its name. Templates can use [Funcs]. All other files are copied verbatim with [CopyFile]
+// and directories become a [Dir]. Templates are parsed immediately, so syntax errors are returned
+// before anything is generated.
+func FromTemplateFS(fsys fs.FS, root string, data any) ([]File, error) {
+	entries, err := fs.ReadDir(fsys, root)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]File, 0, len(entries))
+
+	for _, entry := range entries {
+		p := path.Join(root, entry.Name())
+
+		if entry.IsDir() {
+			children, err := FromTemplateFS(fsys, p, data)
+			if err != nil {
+				return nil, err
+			}
+
+			files = append(files, Dir(entry.Name(), children...))
+
+			continue
+		}
+
+		name, isTemplate := strings.CutSuffix(entry.Name(), TemplateSuffix)
+		if !isTemplate {
+			files = append(files, CopyFile(name, fsys, p))
+			continue
+		}
+
+		contents, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return nil, err
+		}
+
+		t, err := template.New(p).Funcs(Funcs()).Parse(string(contents))
+		if err != nil {
+			return nil, fmt.Errorf("error parsing template %s: %w", p, err)
+		}
+
+		files = append(files, FileFromTemplate(name, t, data))
+	}
+
+	return files, nil
+}
@@ -0,0 +1,45 @@
+package drydock
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromTemplateFS(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	blueprint := fstest.MapFS{
+		"blueprint/go.mod.tmpl":             &fstest.MapFile{Data: []byte("module {{ .Module }}\n")},
+		"blueprint/README.md":               &fstest.MapFile{Data: []byte("# {{ .Module }}  \n\n\n")},
+		"blueprint/cmd/main.go.tmpl":        &fstest.MapFile{Data: []byte("package main\n\n// {{ .Module }}\n")},
+		"blueprint/internal/.gitkeep":       &fstest.MapFile{},
+		"other/ignored.txt":                 &fstest.MapFile{},
+		"blueprint/internal/empty/.gitkeep": &fstest.MapFile{},
+	}
+
+	files, err := FromTemplateFS(blueprint, "blueprint", map[string]string{"Module": "example.com/app"})
+	assert.NoError(t, err)
+
+	tmpdir := WritableMapFS{}
+
+	err = (&FSGenerator{FS: tmpdir, Formatters: DefaultFormatters()}).Generate(ctx, files...)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "module example.com/app\n", string(tmpdir["go.mod"].Data))
+	assert.Equal(t, "# {{ .Module }}  \n\n\n", string(tmpdir["README.md"].Data))
+	assert.Equal(t, "package main\n\n// example.com/app\n", string(tmpdir["cmd/main.go"].Data))
+	assert.Contains(t, tmpdir, "internal/.gitkeep")
+	assert.Contains(t, tmpdir, "internal/empty/.gitkeep")
+	assert.NotContains(t, tmpdir, "ignored.txt")
+	assert.NotContains(t, tmpdir, "go.mod.tmpl")
+
+	_, err = FromTemplateFS(fstest.MapFS{"a.tmpl": &fstest.MapFile{Data: []byte("{{ .A")}}, ".", nil)
+	assert.EqualError(t, err, "error parsing template a.tmpl: template: a.tmpl:1: unclosed action")
+
+	_, err = FromTemplateFS(blueprint, "missing", nil)
+	assert.Error(t, err)
+}
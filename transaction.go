@@ -0,0 +1,194 @@
+package drydock
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+)
+
+// ErrRollback is returned when a transactional [FSGenerator.Generate] failed and
+// the [WritableFS] could not be restored to its previous state.
+var ErrRollback = errors.New("error rolling back generation")
+
+type journalOp int
+
+const (
+	journalCreated journalOp = iota
+	journalOverwritten
+	journalRemoved
+)
+
+type journalEntry struct {
+	op      journalOp
+	path    string
+	isDir   bool
+	backup  []byte
+	mode    fs.FileMode
+	symlink string
+}
+
+// journal records every change made to a [WritableFS] during a transactional
+// generation so it can be undone.
+type journal struct {
+	entries []journalEntry
+}
+
+func (j *journal) created(p string, isDir bool) {
+	j.entries = append(j.entries, journalEntry{op: journalCreated, path: p, isDir: isDir})
+}
+
+func (j *journal) overwritten(p string, backup []byte, mode fs.FileMode) {
+	j.entries = append(j.entries, journalEntry{op: journalOverwritten, path: p, backup: backup, mode: mode})
+}
+
+func (j *journal) removed(p string, isDir bool, backup []byte, mode fs.FileMode) {
+	j.entries = append(j.entries, journalEntry{op: journalRemoved, path: p, isDir: isDir, backup: backup, mode: mode})
+}
+
+func (j *journal) removedSymlink(p string, target string) {
+	j.entries = append(j.entries, journalEntry{op: journalRemoved, path: p, symlink: target})
+}
+
+// snapshotRemoval records the contents of dir before it gets removed. Entries are
+// recorded children first, so that rolling back in reverse recreates parents first.
+func (j *journal) snapshotRemoval(fsys WritableFS, dir string) error {
+	removed := []journalEntry{}
+
+	err := fs.WalkDir(fsys, dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if p == "." {
+			return nil
+		}
+
+		if d.Type()&fs.ModeSymlink != 0 {
+			target, _, err := readlink(fsys, p)
+			if err != nil {
+				return err
+			}
+
+			removed = append(removed, journalEntry{op: journalRemoved, path: p, symlink: target})
+
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			removed = append(removed, journalEntry{op: journalRemoved, path: p, isDir: true, mode: info.Mode().Perm()})
+			return nil
+		}
+
+		data, err := fsys.ReadFile(p)
+		if err != nil {
+			return err
+		}
+
+		removed = append(removed, journalEntry{op: journalRemoved, path: p, backup: data, mode: info.Mode().Perm()})
+
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+
+	slices.Reverse(removed)
+
+	j.entries = append(j.entries, removed...)
+
+	return nil
+}
+
+// rollback undoes all journaled changes in reverse order.
+func (j *journal) rollback(fsys WritableFS) error {
+	var errs []error
+
+	for i := len(j.entries) - 1; i >= 0; i-- {
+		e := j.entries[i]
+
+		var err error
+
+		switch e.op {
+		case journalCreated:
+			err = fsys.Remove(e.path)
+		case journalOverwritten:
+			err = writeFile(fsys, e.path, e.backup, e.mode)
+		case journalRemoved:
+			err = restore(fsys, e)
+		}
+
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	j.entries = nil
+
+	if len(errs) != 0 {
+		return fmt.Errorf("%w: %w", ErrRollback, errors.Join(errs...))
+	}
+
+	return nil
+}
+
+func restore(fsys WritableFS, e journalEntry) error {
+	switch {
+	case e.isDir:
+		return mkdir(fsys, e.path, e.mode)
+	case e.symlink != "":
+		return createSymlink(fsys, e.symlink, e.path)
+	default:
+		return writeFile(fsys, e.path, e.backup, e.mode)
+	}
+}
+
+func mkdir(fsys WritableFS, p string, mode fs.FileMode) error {
+	if mode == 0 {
+		return fsys.Mkdir(p, defaultDirMode)
+	}
+
+	err := fsys.Mkdir(p, mode)
+	if err != nil {
+		return err
+	}
+
+	return chmod(fsys, p, mode)
+}
+
+// writeFile atomically replaces the contents of the file p.
+func writeFile(fsys WritableFS, p string, data []byte, mode fs.FileMode) (err error) {
+	tmpfile, err := fsys.CreateTemp("", path.Base(p))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, tmpfile.Close())
+
+		if err != nil {
+			err = errors.Join(err, removeTemp(fsys, tmpfile))
+		}
+	}()
+
+	_, err = tmpfile.Write(data)
+	if err != nil {
+		return err
+	}
+
+	err = fsys.Rename(tmpfile.Name(), p)
+	if err != nil {
+		return err
+	}
+
+	return chmod(fsys, p, fileMode(mode, 0))
+}
@@ -0,0 +1,150 @@
+package drydock
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errFailingFile = errors.New("failing file")
+
+type cancelFile struct {
+	name   string
+	cancel context.CancelFunc
+}
+
+func (f *cancelFile) Name() string {
+	return f.name
+}
+
+func (f *cancelFile) WriteTo(w io.Writer) (int64, error) {
+	f.cancel()
+	n, err := w.Write([]byte(f.name))
+	return int64(n), err
+}
+
+func TestFSGenerator_Generate_Transactional(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmpdir := WritableMapFS{
+		"README.md": &fstest.MapFile{Data: []byte("old readme")},
+		"pkg":       &fstest.MapFile{Mode: fs.ModeDir | 0755},
+		"pkg/a.go":  &fstest.MapFile{Data: []byte("package pkg")},
+	}
+
+	g := &FSGenerator{FS: tmpdir, Transactional: true}
+
+	err := g.Generate(ctx,
+		PlainFile("README.md", "new readme"),
+		Dir("bin",
+			Dir("cli",
+				PlainFile("main.go", "package main"),
+			),
+		),
+		Dir("pkg",
+			PlainFile("a.go", "package pkg // modified"),
+			&erroringFile{name: "b.go", err: errFailingFile},
+		),
+	)
+	assert.ErrorIs(t, err, errFailingFile)
+
+	assert.Equal(t, WritableMapFS{
+		"README.md": &fstest.MapFile{Data: []byte("old readme")},
+		"pkg":       &fstest.MapFile{Mode: fs.ModeDir | 0755},
+		"pkg/a.go":  &fstest.MapFile{Data: []byte("package pkg")},
+	}, stripModes(tmpdir))
+}
+
+func TestFSGenerator_Generate_Transactional_CleanDir(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmpdir := WritableMapFS{
+		"README.md":  &fstest.MapFile{Data: []byte("old readme")},
+		"pkg":        &fstest.MapFile{Mode: fs.ModeDir | 0755},
+		"pkg/a.go":   &fstest.MapFile{Data: []byte("package pkg")},
+		"pkg/b":      &fstest.MapFile{Mode: fs.ModeDir | 0755},
+		"pkg/b/b.go": &fstest.MapFile{Data: []byte("package b")},
+	}
+
+	g := &FSGenerator{FS: tmpdir, Transactional: true, CleanDir: true}
+
+	err := g.Generate(ctx,
+		PlainFile("README.md", "new readme"),
+		&erroringFile{name: "fail", err: errFailingFile},
+	)
+	assert.ErrorIs(t, err, errFailingFile)
+
+	assert.Equal(t, WritableMapFS{
+		"README.md":  &fstest.MapFile{Data: []byte("old readme")},
+		"pkg":        &fstest.MapFile{Mode: fs.ModeDir | 0755},
+		"pkg/a.go":   &fstest.MapFile{Data: []byte("package pkg")},
+		"pkg/b":      &fstest.MapFile{Mode: fs.ModeDir | 0755},
+		"pkg/b/b.go": &fstest.MapFile{Data: []byte("package b")},
+	}, stripModes(tmpdir))
+}
+
+func TestFSGenerator_Generate_Transactional_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmpdir := WritableMapFS{}
+
+	g := &FSGenerator{FS: tmpdir, Transactional: true}
+
+	err := g.Generate(ctx,
+		Dir("bin",
+			&cancelFile{name: "a", cancel: cancel},
+			PlainFile("b", "b"),
+		),
+	)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, tmpdir)
+}
+
+func TestDirFSGenerator_Generate_Transactional(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmpdir := t.TempDir()
+
+	err := os.WriteFile(path.Join(tmpdir, "README.md"), []byte("old readme"), 0644)
+	assert.NoError(t, err)
+
+	g := &FSGenerator{FS: NewWritableDirFS(tmpdir), Transactional: true}
+
+	err = g.Generate(ctx,
+		PlainFile("README.md", "new readme"),
+		Dir("bin", Dir("cli", PlainFile("main.go", "package main"))),
+		&erroringFile{name: "fail", err: errFailingFile},
+	)
+	assert.ErrorIs(t, err, errFailingFile)
+
+	entries, err := os.ReadDir(tmpdir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	readme, err := os.ReadFile(path.Join(tmpdir, "README.md"))
+	assert.NoError(t, err)
+	assert.Equal(t, "old readme", string(readme))
+}
+
+// stripModes removes the file modes of non directories, as restored files are
+// written with the default mode of the FS.
+func stripModes(wmfs WritableMapFS) WritableMapFS {
+	for _, f := range wmfs {
+		if !f.Mode.IsDir() {
+			f.Mode = 0
+		}
+	}
+
+	return wmfs
+}
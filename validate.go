@@ -0,0 +1,177 @@
+package drydock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+var (
+	// ErrInvalidName is returned for names which are empty, `.`, `..` or contain a `/`.
+	ErrInvalidName = errors.New("invalid name")
+
+	// ErrDuplicatePath is returned for paths which are used by more than one file, by a file and
+	// a directory, or which only differ in case and would collide on case-insensitive file systems.
+	ErrDuplicatePath = errors.New("duplicate path")
+
+	// ErrInvalidTemplate is returned for templates which can't be parsed, e.g. by [TemplateFile].
+	ErrInvalidTemplate = errors.New("invalid template")
+)
+
+// Validate checks the file tree for invalid names, duplicate paths and invalid templates without
+// generating anything. All problems are returned joined. The entries of a [LazyDirectory] are not
+// validated, as they are only known during generation. [FSGenerator.Generate] and [FSGenerator.Plan] validate the
+// file tree before doing anything else. Directories with the same path are merged and files
+// modifying existing files, like [ModifyFile], may share their path with another file.
+func Validate(files ...File) error {
+	gendirs, genfiles, err := (&FSGenerator{}).collect(context.Background(), nil, files)
+	if err != nil {
+		return err
+	}
+
+	return validate(gendirs, genfiles)
+}
+
+// renderName executes the name template with data and opts. Missing keys are always an error.
+// If the template fails, name is returned together with the error.
+func renderName(name string, data any, opts templateOptions) (string, error) {
+	opts.missingKeyError = true
+
+	t, err := opts.newTemplate("name")
+	if err != nil {
+		return name, err
+	}
+
+	t, err = t.Parse(name)
+	if err != nil {
+		return name, err
+	}
+
+	var b strings.Builder
+
+	err = t.Execute(&b, data)
+	if err != nil {
+		return name, err
+	}
+
+	return b.String(), nil
+}
+
+// nameErrorOf returns the error of rendering the name of f, see [TemplateNamedFile].
+func nameErrorOf(f File) error {
+	if n, ok := f.(interface{ nameError() error }); ok {
+		return n.nameError()
+	}
+
+	return nil
+}
+
+// templateErrorOf returns the error of parsing the template of f, see [TemplateFile].
+func templateErrorOf(f File) error {
+	if t, ok := f.(interface{ templateError() error }); ok {
+		return t.templateError()
+	}
+
+	return nil
+}
+
+func validate(gendirs []*gendir, genfiles []*genfile) error {
+	v := &validator{isDir: map[string]bool{}, folded: map[string]string{}}
+
+	for _, d := range gendirs {
+		if v.checkName(d.name, d.path, d.nameErr) {
+			v.check(d.path, true)
+		}
+	}
+
+	for _, f := range genfiles {
+		if !v.checkName(f.name, f.path, f.nameErr) {
+			continue
+		}
+
+		if f.templateErr != nil {
+			v.problems = append(v.problems, fmt.Errorf("%w %s: %w", ErrInvalidTemplate, f.path, f.templateErr))
+		}
+
+		if f.isNewFile {
+			v.check(f.path, false)
+		} else {
+			v.checkModified(f.path)
+		}
+	}
+
+	return errors.Join(v.problems...)
+}
+
+type validator struct {
+	problems []error
+	// isDir tracks every valid path and whether it's a directory.
+	isDir map[string]bool
+	// folded maps every valid path with a lower case base name to the path.
+	folded map[string]string
+}
+
+// checkName reports whether name is valid. nameErr is the error rendering the name, if any.
+func (v *validator) checkName(name string, p string, nameErr error) bool {
+	if nameErr != nil {
+		v.problems = append(v.problems, fmt.Errorf("%w %q: %w", ErrInvalidName, p, nameErr))
+		return false
+	}
+
+	if reason := invalidName(name); reason != "" {
+		v.problems = append(v.problems, fmt.Errorf("%w %q: %s", ErrInvalidName, p, reason))
+		return false
+	}
+
+	return true
+}
+
+func (v *validator) check(p string, isDir bool) {
+	if wasDir, ok := v.isDir[p]; ok {
+		switch {
+		case isDir && wasDir:
+			// directories are merged
+		case isDir != wasDir:
+			v.problems = append(v.problems, fmt.Errorf("%w: %s is a file and a directory", ErrDuplicatePath, p))
+		default:
+			v.problems = append(v.problems, fmt.Errorf("%w: %s", ErrDuplicatePath, p))
+		}
+
+		return
+	}
+
+	v.isDir[p] = isDir
+
+	// only siblings are compared, collisions of the parents are reported for the parents
+	folded := path.Join(path.Dir(p), strings.ToLower(path.Base(p)))
+	if other, ok := v.folded[folded]; ok {
+		v.problems = append(v.problems, fmt.Errorf("%w: %s and %s only differ in case", ErrDuplicatePath, other, p))
+		return
+	}
+
+	v.folded[folded] = p
+}
+
+// checkModified checks files modifying existing files, like [ModifyFile], which may share
+// their path with another file.
+func (v *validator) checkModified(p string) {
+	if v.isDir[p] {
+		v.problems = append(v.problems, fmt.Errorf("%w: %s is a file and a directory", ErrDuplicatePath, p))
+	}
+}
+
+// invalidName returns why name is invalid or an empty string if it's valid.
+func invalidName(name string) string {
+	switch {
+	case name == "":
+		return "name is empty"
+	case name == "." || name == "..":
+		return "name must not be . or .."
+	case strings.Contains(name, "/"):
+		return "name must not contain /, use DirP for nested directories"
+	default:
+		return ""
+	}
+}
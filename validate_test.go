@@ -0,0 +1,144 @@
+package drydock
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	tt := []struct {
+		name   string
+		files  []File
+		errors []string
+	}{
+		{
+			name: "Valid",
+			files: []File{
+				Dir("bin", PlainFile("script.sh", "")),
+				Dir("bin", PlainFile("other.sh", "")),
+				DirP("a/b/c", PlainFile("d", "")),
+				PlainFile("config.json", "{}"),
+				ModifyFile("config.json", json.Unmarshal, func(m *map[string]any) ([]byte, error) { return json.Marshal(m) }),
+				Symlink("link", "config.json"),
+			},
+		},
+		{
+			name: "Invalid Names",
+			files: []File{
+				PlainFile("../../etc/x", ""),
+				PlainFile("", ""),
+				Dir("dir", PlainFile("..", ""), Dir(".")),
+				DirP("/etc", PlainFile("passwd", "")),
+				DirP("a/../b"),
+			},
+			errors: []string{
+				`invalid name "dir/.": name must not be . or ..`,
+				`invalid name "": name is empty`,
+				`invalid name "a/..": name must not be . or ..`,
+				`invalid name "../../etc/x": name must not contain /, use DirP for nested directories`,
+				`invalid name "": name is empty`,
+				`invalid name "dir/..": name must not be . or ..`,
+			},
+		},
+		{
+			name: "Duplicates",
+			files: []File{
+				PlainFile("README.md", ""),
+				Dir("pkg", PlainFile("a.go", ""), PlainFile("a.go", "")),
+				Dir("README.md"),
+				Symlink("pkg", "README.md"),
+				ModifyFile("pkg", json.Unmarshal, func(m *map[string]any) ([]byte, error) { return json.Marshal(m) }),
+			},
+			errors: []string{
+				"duplicate path: README.md is a file and a directory",
+				"duplicate path: pkg/a.go",
+				"duplicate path: pkg is a file and a directory",
+				"duplicate path: pkg is a file and a directory",
+			},
+		},
+		{
+			name: "Case Insensitive",
+			files: []File{
+				Dir("Docs", PlainFile("README.md", "")),
+				Dir("docs", PlainFile("README.md", ""), PlainFile("readme.md", "")),
+			},
+			errors: []string{
+				"duplicate path: Docs and docs only differ in case",
+				"duplicate path: docs/README.md and docs/readme.md only differ in case",
+			},
+		},
+		{
+			name: "Templated Names",
+			files: []File{
+				TemplateNamedDir("{{ .Module }}", map[string]string{"Module": "github.com/x/y"}),
+				TemplateNamedDir("{{ .Service }}", map[string]string{"Service": "users"},
+					TemplateNamedFile("{{ .Missing }}.go", "", map[string]string{}),
+					TemplateNamedFile("{{ .Service", "", map[string]string{}),
+				),
+			},
+			errors: []string{
+				`invalid name "github.com/x/y": name must not contain /, use DirP for nested directories`,
+				`invalid name "users/{{ .Missing }}.go": template: name:1:3: executing "name" at <.Missing>: map has no entry for key "Missing"`,
+				`invalid name "users/{{ .Service": template: name:1: unclosed action`,
+			},
+		},
+		{
+			name: "Invalid Templates",
+			files: []File{
+				Dir("cmd", TemplateFile("main.go", "{{ .Name", nil)),
+				TemplateNamedFile("{{ .Name }}.go", "{{ end }}", map[string]string{"Name": "app"}),
+			},
+			errors: []string{
+				"invalid template cmd/main.go: template: main.go:1: unclosed action",
+				"invalid template app.go: template: app.go:1: unexpected {{end}}",
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Validate(tc.files...)
+			if len(tc.errors) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			errs := []string{}
+			for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+				errs = append(errs, e.Error())
+			}
+
+			assert.Equal(t, tc.errors, errs)
+		})
+	}
+}
+
+func TestFSGenerator_Generate_Validate(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tmpdir := WritableMapFS{"keep": nil}
+
+	g := &FSGenerator{FS: tmpdir, CleanDir: true}
+
+	err := g.Generate(ctx, PlainFile("a", "a"), PlainFile("../../etc/x", "x"), PlainFile("a", "b"))
+	assert.ErrorIs(t, err, ErrInvalidName)
+	assert.ErrorIs(t, err, ErrDuplicatePath)
+
+	var genErr *GenerateError
+	if assert.ErrorAs(t, err, &genErr) {
+		assert.Equal(t, "validate", genErr.Op)
+	}
+
+	assert.Equal(t, WritableMapFS{"keep": nil}, tmpdir)
+
+	_, err = g.Plan(ctx, PlainFile("a", "a"), PlainFile("A", "A"))
+	assert.ErrorIs(t, err, ErrDuplicatePath)
+
+	err = g.Generate(ctx, PlainFile("a", "a"), TemplateFile("b", "{{ .B", nil))
+	assert.ErrorIs(t, err, ErrInvalidTemplate)
+	assert.Equal(t, WritableMapFS{"keep": nil}, tmpdir)
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path"
 	"strings"
+	"time"
 )
 
 // WritableFS extends the standard [io/fs.FS] interface with writing capabilities for
@@ -30,6 +31,72 @@ type WritableFS interface {
 	CreateTemp(dir string, pattern string) (WritableFile, error)
 }
 
+// ChmodFS is an optional capability of a [WritableFS] to change the mode of a file.
+type ChmodFS interface {
+	WritableFS
+
+	// Chmod should behave like [os.Chmod].
+	Chmod(name string, mode fs.FileMode) error
+}
+
+// chmod changes the mode of name if fsys implements [ChmodFS].
+func chmod(fsys WritableFS, name string, mode fs.FileMode) error {
+	chmodFS, ok := fsys.(ChmodFS)
+	if !ok {
+		return nil
+	}
+
+	err := chmodFS.Chmod(name, mode)
+	if errors.Is(err, errors.ErrUnsupported) {
+		return nil
+	}
+
+	return err
+}
+
+// ChtimesFS is an optional capability of a [WritableFS] to change the modification time of a file.
+type ChtimesFS interface {
+	WritableFS
+
+	// Chtimes should behave like [os.Chtimes].
+	Chtimes(name string, atime time.Time, mtime time.Time) error
+}
+
+// chtimes changes the access and modification time of name to mtime if fsys implements [ChtimesFS].
+func chtimes(fsys WritableFS, name string, mtime time.Time) error {
+	chtimesFS, ok := fsys.(ChtimesFS)
+	if !ok {
+		return nil
+	}
+
+	err := chtimesFS.Chtimes(name, mtime, mtime)
+	if errors.Is(err, errors.ErrUnsupported) {
+		return nil
+	}
+
+	return err
+}
+
+// removeTemp removes tmpfile created by fsys.CreateTemp. Temp files of the real file system are
+// outside of fsys, so they are removed directly. Wrapped files, e.g. of [FSGenerator.Concurrency],
+// are unwrapped first.
+func removeTemp(fsys WritableFS, tmpfile WritableFile) error {
+	f := tmpfile
+	for {
+		u, ok := f.(interface{ Unwrap() WritableFile })
+		if !ok {
+			break
+		}
+		f = u.Unwrap()
+	}
+
+	if _, ok := f.(*os.File); ok {
+		return os.Remove(tmpfile.Name())
+	}
+
+	return fsys.Remove(tmpfile.Name())
+}
+
 type WritableFile interface {
 	fs.File
 	io.Writer
@@ -92,6 +159,22 @@ func (wfs *writableDirFS) RemoveAll(p string) error {
 	return os.RemoveAll(path.Join(wfs.baseDir, p))
 }
 
+func (wfs *writableDirFS) Chmod(name string, mode fs.FileMode) error {
+	return os.Chmod(path.Join(wfs.baseDir, name), mode)
+}
+
+func (wfs *writableDirFS) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	return os.Chtimes(path.Join(wfs.baseDir, name), atime, mtime)
+}
+
+func (wfs *writableDirFS) Symlink(oldname string, newname string) error {
+	return os.Symlink(oldname, path.Join(wfs.baseDir, newname))
+}
+
+func (wfs *writableDirFS) Readlink(name string) (string, error) {
+	return os.Readlink(path.Join(wfs.baseDir, name))
+}
+
 func (wfs *writableDirFS) CreateTemp(dir string, pattern string) (WritableFile, error) {
 	return os.CreateTemp(dir, pattern)
 }
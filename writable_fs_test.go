@@ -0,0 +1,27 @@
+package drydock
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWritableDirFS_Remove(t *testing.T) {
+	outside := filepath.Join(t.TempDir(), "outside")
+	assert.NoError(t, os.WriteFile(outside, []byte("keep"), 0600))
+
+	fsys := NewWritableDirFS(t.TempDir())
+
+	err := fsys.Remove(outside)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	assert.FileExists(t, outside)
+
+	tmpfile, err := fsys.CreateTemp("", "tmp")
+	assert.NoError(t, err)
+	assert.NoError(t, tmpfile.Close())
+
+	assert.NoError(t, removeTemp(fsys, tmpfile))
+	assert.NoFileExists(t, tmpfile.Name())
+}
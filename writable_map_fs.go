@@ -5,6 +5,7 @@ import (
 	"io/fs"
 	"os"
 	"path"
+	"strings"
 	"syscall"
 	"testing/fstest"
 	"time"
@@ -13,7 +14,11 @@ import (
 // WritableMapFS extends [testing/fstest.MapFS] with [WritableFS] capabilities.
 type WritableMapFS fstest.MapFS
 
-var _ WritableFS = (*WritableMapFS)(nil)
+var (
+	_ ChmodFS   = (*WritableMapFS)(nil)
+	_ ChtimesFS = (*WritableMapFS)(nil)
+	_ SymlinkFS = (*WritableMapFS)(nil)
+)
 
 func (fsys WritableMapFS) Glob(pattern string) ([]string, error) {
 	return fstest.MapFS(fsys).Glob(pattern)
@@ -65,24 +70,26 @@ func (fsys WritableMapFS) Rename(oldpath string, newpath string) error {
 	return nil
 }
 
-func (fsys WritableMapFS) Remove(path string) error {
-	if _, exists := fsys[path]; exists {
-		return &fs.PathError{Op: "remove", Path: path, Err: fs.ErrExist}
+func (fsys WritableMapFS) Remove(name string) error {
+	if _, exists := fsys[name]; !exists {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+
+	for p := range fsys {
+		if strings.HasPrefix(p, name+"/") {
+			return &fs.PathError{Op: "remove", Path: name, Err: syscall.ENOTEMPTY}
+		}
 	}
 
-	delete(fsys, path)
+	delete(fsys, name)
 
 	return nil
 }
 
 func (fsys WritableMapFS) RemoveAll(dir string) error {
-	if _, exists := fsys[dir]; !exists && dir != "." {
-		return &fs.PathError{Op: "remove", Path: dir, Err: fs.ErrExist}
-	}
-
 	toRemove := []string{}
 	for p := range fsys {
-		if p == dir || path.Dir(p) == dir {
+		if dir == "." || p == dir || strings.HasPrefix(p, dir+"/") {
 			toRemove = append(toRemove, p)
 		}
 	}
@@ -94,6 +101,53 @@ func (fsys WritableMapFS) RemoveAll(dir string) error {
 	return nil
 }
 
+func (fsys WritableMapFS) Chmod(name string, mode fs.FileMode) error {
+	file, ok := fsys[name]
+	if !ok {
+		return &fs.PathError{Op: "chmod", Path: name, Err: fs.ErrNotExist}
+	}
+
+	file.Mode = file.Mode.Type() | mode.Perm()
+
+	return nil
+}
+
+// Chtimes sets the ModTime of the file to mtime, atime is ignored.
+func (fsys WritableMapFS) Chtimes(name string, _ time.Time, mtime time.Time) error {
+	file, ok := fsys[name]
+	if !ok {
+		return &fs.PathError{Op: "chtimes", Path: name, Err: fs.ErrNotExist}
+	}
+
+	file.ModTime = mtime
+
+	return nil
+}
+
+// Symlink stores the link as a file with [fs.ModeSymlink] and target as its data.
+func (fsys WritableMapFS) Symlink(oldname string, newname string) error {
+	if _, exists := fsys[newname]; exists {
+		return &os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: fs.ErrExist}
+	}
+
+	fsys[newname] = &fstest.MapFile{Data: []byte(oldname), Mode: fs.ModeSymlink | 0777}
+
+	return nil
+}
+
+func (fsys WritableMapFS) Readlink(name string) (string, error) {
+	file, ok := fsys[name]
+	if !ok {
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: fs.ErrNotExist}
+	}
+
+	if file.Mode&fs.ModeSymlink == 0 {
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: syscall.EINVAL}
+	}
+
+	return string(file.Data), nil
+}
+
 // CreateTemp does not implement the pattern function of [os.CreateTemp].
 // The default temp dir is `/tmp`.
 func (fsys WritableMapFS) CreateTemp(dir string, pattern string) (WritableFile, error) {